@@ -3,9 +3,11 @@ package app
 
 import (
 	"fmt"
+	"slices"
 
 	"service-uptime-center/internal/app/apperror"
 	"service-uptime-center/internal/app/timings"
+	"service-uptime-center/internal/checker"
 	"service-uptime-center/internal/service"
 	"service-uptime-center/notification"
 )
@@ -33,8 +35,12 @@ type Config struct {
 	Notification      notification.ManagerConfig `yaml:"notification_settings"`
 	Service           service.Config             `yaml:"service_settings"`
 	Timings           timings.Timings            `yaml:"time_settings"`
+	Checks            checker.Config             `yaml:"check_settings"`
 	Notifiers         []string                   `yaml:"notifiers"`
 	FallbackNotifiers []string                   `yaml:"fallback_notifiers"`
+	WarningNotifiers  []string                   `yaml:"warning_notifiers"`
+	// ShutdownNotice sends a notification to the notifiers when the monitor shuts down.
+	ShutdownNotice bool `yaml:"shutdown_notice"`
 }
 
 func (a *Config) Validate() error {
@@ -46,6 +52,14 @@ func (a *Config) Validate() error {
 		return err
 	}
 
+	services := make(map[string]struct{}, len(a.Service.Services))
+	for _, service := range a.Service.Services {
+		services[service.Name] = struct{}{}
+	}
+	if err := a.Checks.Validate(services); err != nil {
+		return err
+	}
+
 	notificationManager := notification.NewManager(&a.Notification)
 	if err := a.Notification.ValidateFor(a.Notifiers, notificationManager); err != nil {
 		return err
@@ -53,6 +67,25 @@ func (a *Config) Validate() error {
 	if err := a.Notification.ValidateFor(a.FallbackNotifiers, notificationManager); err != nil {
 		return err
 	}
+	if err := a.Notification.ValidateFor(a.WarningNotifiers, notificationManager); err != nil {
+		return err
+	}
+
+	for _, service := range a.Service.Services {
+		if err := a.validateNotifiers(notificationManager, service.Notifiers, service.WarningNotifiers); err != nil {
+			return fmt.Errorf("service %s: %w", service.Name, err)
+		}
+	}
+	for _, template := range a.Service.AutoProvision {
+		if err := a.validateNotifiers(notificationManager, template.Notifiers, template.WarningNotifiers); err != nil {
+			return fmt.Errorf("auto provisioning template %s: %w", template.Pattern, err)
+		}
+	}
+	for _, route := range a.Service.Routes {
+		if err := a.validateNotifiers(notificationManager, route.Notifiers, route.WarningNotifiers); err != nil {
+			return fmt.Errorf("route for tags %v: %w", route.Tags, err)
+		}
+	}
 
 	if len(a.FallbackNotifiers) != 0 {
 		seen := make(map[string]struct{}, len(a.Notifiers))
@@ -68,3 +101,35 @@ func (a *Config) Validate() error {
 
 	return nil
 }
+
+// validateNotifiers validates every list of notifiers on its own, a protocol may be in several of the lists, e.g.
+// both the notifiers and the warning notifiers of a service.
+func (a *Config) validateNotifiers(notificationManager *notification.Manager, lists ...[]string) error {
+	for _, notifiers := range lists {
+		if err := a.Notification.ValidateFor(notifiers, notificationManager); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllNotifiers returns every notifier referenced anywhere in the config without duplicates, these are the ones
+// that have been validated and may be used at runtime.
+func (a *Config) AllNotifiers() []string {
+	all := slices.Concat(a.Notifiers, a.FallbackNotifiers, a.WarningNotifiers)
+	for _, service := range a.Service.Services {
+		all = append(all, service.Notifiers...)
+		all = append(all, service.WarningNotifiers...)
+	}
+	for _, template := range a.Service.AutoProvision {
+		all = append(all, template.Notifiers...)
+		all = append(all, template.WarningNotifiers...)
+	}
+	for _, route := range a.Service.Routes {
+		all = append(all, route.Notifiers...)
+		all = append(all, route.WarningNotifiers...)
+	}
+
+	slices.Sort(all)
+	return slices.Compact(all)
+}
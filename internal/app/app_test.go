@@ -0,0 +1,35 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"service-uptime-center/internal/service"
+	"service-uptime-center/notification"
+)
+
+func TestValidateNotifiersOfServices(t *testing.T) {
+	cfg := Config{
+		Notification: notification.ManagerConfig{
+			Ntfy: notification.NtfyConfig{Server: "https://ntfy.example.com", Topic: "alerts"},
+		},
+		Service: service.Config{
+			Services: []service.Service{{
+				Name:                     "api-server",
+				HeartbeatTimeoutDuration: time.Hour,
+				Notifiers:                []string{"ntfy"},
+				WarningNotifiers:         []string{"ntfy"},
+			}},
+		},
+		Notifiers: []string{"ntfy"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected a notifier in both the notifiers and warning notifiers of a service to be valid, got %v", err)
+	}
+
+	cfg.Service.Services[0].WarningNotifiers = []string{"ntfy", "ntfy"}
+	if err := cfg.Validate(); !errors.Is(err, notification.ErrDuplicateNotifyProtocol) {
+		t.Errorf("expected ErrDuplicateNotifyProtocol for a notifier listed twice, got %v", err)
+	}
+}
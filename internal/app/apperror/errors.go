@@ -8,9 +8,30 @@ var (
 	ErrPasswordTooLong          = errors.New("password token too long")
 	ErrHeartbeatTimeoutTooShort = errors.New("heartbeat timeout duration too short")
 	ErrInvalidServiceName       = errors.New("invalid service name length")
+	ErrInvalidWarningThreshold  = errors.New("invalid warning threshold")
+	ErrInvalidPulsePolicy       = errors.New("invalid pulse policy")
+	ErrInvalidMetricRule        = errors.New("invalid metric rule")
+	ErrInvalidCheck             = errors.New("invalid check")
 	ErrDuplicateServiceNames    = errors.New("duplicate server names detected, not allowed")
 	ErrNoNotifiers              = errors.New("service is missing notifiers, not allowed")
 	ErrInvalidNotifProtocol     = errors.New("notification protocol doesn't exist")
+	ErrServiceNotFound          = errors.New("service doesn't exist")
+	ErrServiceNotProblematic    = errors.New("service is not problematic, nothing to acknowledge")
+	ErrInvalidAckExpiry         = errors.New("acknowledgement expiry must be positive")
+	ErrInvalidMaintenanceWindow = errors.New("invalid maintenance window")
+	ErrInvalidSilence           = errors.New("invalid silence")
+	ErrSilenceNotFound          = errors.New("silence doesn't exist")
+	ErrServiceNotPaused         = errors.New("service is not paused")
+	ErrStaticService            = errors.New("service is declared in the config and can't be modified at runtime")
+	ErrInvalidServiceDefinition = errors.New("invalid service definition")
+	ErrInvalidProvisionTemplate = errors.New("invalid auto provisioning template")
+	ErrInvalidRoute             = errors.New("invalid notification route")
+	ErrUnknownDependency        = errors.New("service depends on a service that doesn't exist")
+	ErrDependencyCycle          = errors.New("service dependencies form a cycle")
+	ErrServiceHasDependents     = errors.New("service is depended on by other services")
+	ErrInvalidFlappingSettings  = errors.New("invalid flapping settings")
+	ErrInvalidEventSettings     = errors.New("invalid event settings")
+	ErrInvalidSummaryReport     = errors.New("invalid summary report settings")
 )
 
 var (
@@ -18,4 +39,6 @@ var (
 	CodeFailedReadingPasswordFile = 2
 	CodeInvalidCliArgument        = 3
 	CodeAuthTestFailed            = 4
+	CodeServerFailed              = 5
+	CodeShutdownFailed            = 6
 )
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"service-uptime-center/config"
+	"service-uptime-center/internal/checker"
+	"service-uptime-center/internal/service"
+	"service-uptime-center/notification"
+)
+
+// MonitoringInstructions returns the instructions for the service manager derived from the config.
+func (a *Config) MonitoringInstructions() service.MonitoringInstructions {
+	return service.MonitoringInstructions{
+		Timings: &a.Timings,
+		Notifiers: notification.ProtocolTargets{
+			Primary:  a.Notifiers,
+			Fallback: a.FallbackNotifiers,
+		},
+		WarningNotifiers: a.WarningNotifiers,
+	}
+}
+
+// Reloader applies changes of the config file to the running managers and runs the active checks, which are
+// restarted with their new config on every reload.
+type Reloader struct {
+	configPath string
+	managers   *managerLocator
+	// ctx is the context the checks run in, they stop when it's done.
+	ctx   context.Context
+	mutex sync.Mutex
+	cfg   *Config
+	// stopChecks stops the checks of the current config, checksStopped is closed once they stopped.
+	stopChecks    context.CancelFunc
+	checksStopped chan struct{}
+}
+
+// NewReloader starts the checks of cfg, which was read from configPath, they run until ctx is done.
+func NewReloader(ctx context.Context, configPath string, cfg *Config, managers *managerLocator) *Reloader {
+	r := &Reloader{
+		configPath: configPath,
+		managers:   managers,
+		ctx:        ctx,
+		cfg:        cfg,
+	}
+	r.startChecks()
+	return r
+}
+
+// Config returns the config currently in use.
+func (r *Reloader) Config() *Config {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.cfg
+}
+
+// AllNotifiers returns the notifiers of the config currently in use.
+func (r *Reloader) AllNotifiers() []string {
+	return r.Config().AllNotifiers()
+}
+
+// WatchedFiles returns the config file and the secret files referenced by the config currently in use.
+func (r *Reloader) WatchedFiles() []string {
+	return append([]string{r.configPath}, r.Config().Notification.SecretFiles()...)
+}
+
+// Reload parses and validates the config file again, including the authentication tests of its notifiers, and
+// applies it. The previous config stays in use if anything fails.
+func (r *Reloader) Reload() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cfg, err := config.Parse(config.YamlFileDecoder[*Config], r.configPath)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	var authErrs []error
+	for _, result := range notification.NewManager(&cfg.Notification).TestAuth(cfg.AllNotifiers()) {
+		if result.Err != nil {
+			authErrs = append(authErrs, fmt.Errorf("%s: %w", result.Protocol, result.Err))
+		}
+	}
+	if len(authErrs) != 0 {
+		return fmt.Errorf("authentication test failed: %w", errors.Join(authErrs...))
+	}
+
+	if err := r.managers.ServiceManager.Reload(&cfg.Service, cfg.MonitoringInstructions()); err != nil {
+		return err
+	}
+	r.managers.NotificationManager.Reload(&cfg.Notification)
+	r.cfg = cfg
+
+	r.stopChecks()
+	<-r.checksStopped
+	r.managers.ServiceManager.RetainChecks(cfg.Checks.ByService())
+	r.startChecks()
+
+	slog.Info("Applied reloaded config", "path", r.configPath)
+	return nil
+}
+
+// startChecks runs the checks of the current config, callers must hold the mutex unless it's not shared yet.
+func (r *Reloader) startChecks() {
+	ctx, cancel := context.WithCancel(r.ctx)
+	stopped := make(chan struct{})
+	scheduler := checker.NewScheduler(&r.cfg.Checks, r.managers.ServiceManager)
+	go func() {
+		defer close(stopped)
+		scheduler.Run(ctx)
+	}()
+	r.stopChecks = cancel
+	r.checksStopped = stopped
+}
+
+// WaitForChecks waits until the checks stopped after the context passed to NewReloader is done, or until ctx is.
+func (r *Reloader) WaitForChecks(ctx context.Context) error {
+	r.mutex.Lock()
+	stopped := r.checksStopped
+	r.mutex.Unlock()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("checks did not stop in time: %w", ctx.Err())
+	}
+}
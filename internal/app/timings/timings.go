@@ -3,8 +3,12 @@ package timings
 
 import "time"
 
+// DefaultShutdownTimeout is used when ShutdownTimeout is not configured.
+const DefaultShutdownTimeout = 30 * time.Second
+
 type Timings struct {
 	IncidentsPollFreq         time.Duration `yaml:"incident_poll_frequency"`
 	SuccessfulReportCooldown  time.Duration `yaml:"successful_report_cooldown"`
 	ProblematicReportCooldown time.Duration `yaml:"problematic_report_cooldown"`
+	ShutdownTimeout           time.Duration `yaml:"shutdown_timeout"`
 }
@@ -0,0 +1,185 @@
+// Package checker actively probes services and reports the results, complementing the pulses services push themselves.
+package checker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+)
+
+const (
+	DefaultInterval      = time.Minute
+	DefaultTimeout       = 10 * time.Second
+	DefaultMaxConcurrent = 8
+)
+
+type Config struct {
+	// MaxConcurrent limits how many checks run at the same time, further checks wait for a running one to finish.
+	MaxConcurrent int     `yaml:"max_concurrent"`
+	Checks        []Check `yaml:"checks"`
+}
+
+func (c *Config) Validate(services map[string]struct{}) error {
+	if c.MaxConcurrent < 0 {
+		return fmt.Errorf("%w: max_concurrent can't be negative", apperror.ErrInvalidCheck)
+	}
+	for i := range c.Checks {
+		if err := c.Checks[i].validate(services); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IDs returns the ID of every check, in the order of Checks. A check is identified by its type, followed by a
+// counter if its service has several checks of that type, e.g. "http", "tls" and "http#2".
+func (c *Config) IDs() []string {
+	ids := make([]string, 0, len(c.Checks))
+	counts := make(map[[2]string]int, len(c.Checks))
+	for i := range c.Checks {
+		kind := c.Checks[i].kind()
+		key := [2]string{c.Checks[i].Service, kind}
+		counts[key]++
+		if counts[key] == 1 {
+			ids = append(ids, kind)
+		} else {
+			ids = append(ids, fmt.Sprintf("%s#%d", kind, counts[key]))
+		}
+	}
+	return ids
+}
+
+// ByService returns the IDs of the checks of every service that has any.
+func (c *Config) ByService() map[string][]string {
+	byService := make(map[string][]string)
+	for i, id := range c.IDs() {
+		service := c.Checks[i].Service
+		byService[service] = append(byService[service], id)
+	}
+	return byService
+}
+
+func (c *Config) maxConcurrent() int {
+	if c.MaxConcurrent == 0 {
+		return DefaultMaxConcurrent
+	}
+	return c.MaxConcurrent
+}
+
+// Checker is implemented by every type of check.
+type Checker interface {
+	// Validate reports configuration errors and prepares the checker, it's called once before the first Check.
+	Validate() error
+	// Check probes the service once, giving up when ctx is done.
+	Check(ctx context.Context) Result
+}
+
+// Check probes a service every Interval using exactly one of its checkers. A successful check counts as a pulse of
+// the service, a failed one marks it as problematic right away.
+type Check struct {
+	Service  string        `yaml:"service"`
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+	HTTP     *HTTPCheck    `yaml:"http"`
+	TCP      *TCPCheck     `yaml:"tcp"`
+	DNS      *DNSCheck     `yaml:"dns"`
+	TLS      *TLSCheck     `yaml:"tls"`
+	File     *FileCheck    `yaml:"file"`
+	Command  *CommandCheck `yaml:"command"`
+}
+
+// checker returns the configured checker, nil if there is none or more than one.
+func (c *Check) checker() Checker {
+	var checkers []Checker
+	if c.HTTP != nil {
+		checkers = append(checkers, c.HTTP)
+	}
+	if c.TCP != nil {
+		checkers = append(checkers, c.TCP)
+	}
+	if c.DNS != nil {
+		checkers = append(checkers, c.DNS)
+	}
+	if c.TLS != nil {
+		checkers = append(checkers, c.TLS)
+	}
+	if c.File != nil {
+		checkers = append(checkers, c.File)
+	}
+	if c.Command != nil {
+		checkers = append(checkers, c.Command)
+	}
+
+	if len(checkers) != 1 {
+		return nil
+	}
+	return checkers[0]
+}
+
+// kind returns the type of the configured checker, e.g. "http".
+func (c *Check) kind() string {
+	switch {
+	case c.HTTP != nil:
+		return "http"
+	case c.TCP != nil:
+		return "tcp"
+	case c.DNS != nil:
+		return "dns"
+	case c.TLS != nil:
+		return "tls"
+	case c.File != nil:
+		return "file"
+	case c.Command != nil:
+		return "command"
+	}
+	return ""
+}
+
+func (c *Check) validate(services map[string]struct{}) error {
+	if _, ok := services[c.Service]; !ok {
+		return fmt.Errorf("%w: unknown service %q", apperror.ErrInvalidCheck, c.Service)
+	}
+	if c.Interval < 0 || c.Timeout < 0 {
+		return fmt.Errorf("%w: %s: interval and timeout can't be negative", apperror.ErrInvalidCheck, c.Service)
+	}
+	checker := c.checker()
+	if checker == nil {
+		return fmt.Errorf("%w: %s must configure exactly one of http, tcp, dns, tls, file or command", apperror.ErrInvalidCheck, c.Service)
+	}
+	if err := checker.Validate(); err != nil {
+		return fmt.Errorf("%w: %s: %w", apperror.ErrInvalidCheck, c.Service, err)
+	}
+	return nil
+}
+
+func (c *Check) interval() time.Duration {
+	if c.Interval == 0 {
+		return DefaultInterval
+	}
+	return c.Interval
+}
+
+func (c *Check) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+// Result is the outcome of a single check. Message explains why a failed check failed, for a successful check it's
+// an optional status text, or a warning if Warning is set. Metrics are optional measurements taken by the check.
+type Result struct {
+	OK      bool
+	Warning bool
+	Message string
+	Latency time.Duration
+	Metrics map[string]float64
+}
+
+// Reporter receives the check results, the service manager implements it. Results are reported per check, as
+// identified by Config.IDs, since a service may have several checks.
+type Reporter interface {
+	ReportCheckResult(service string, check string, ok bool, warning bool, message string, metrics map[string]float64) error
+}
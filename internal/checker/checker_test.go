@@ -0,0 +1,584 @@
+package checker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			fmt.Fprint(w, `{"status": "ok", "version": "1.2.3"}`)
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/teapot":
+			w.WriteHeader(http.StatusTeapot)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name     string
+		check    HTTPCheck
+		expected string
+	}{
+		{"healthy", HTTPCheck{URL: server.URL + "/health", BodyContains: `"ok"`, BodyRegex: `version": "1\.\d+`}, ""},
+		{"not found", HTTPCheck{URL: server.URL + "/missing"}, "unexpected status 404"},
+		{"expected status", HTTPCheck{URL: server.URL + "/teapot", ExpectedStatus: []int{http.StatusTeapot}}, ""},
+		{"unexpected status", HTTPCheck{URL: server.URL + "/health", ExpectedStatus: []int{http.StatusNoContent}}, "unexpected status 200"},
+		{"too slow", HTTPCheck{URL: server.URL + "/slow", MaxLatency: 10 * time.Millisecond}, "more than the maximum of 10ms"},
+		{"missing substring", HTTPCheck{URL: server.URL + "/health", BodyContains: "degraded"}, `body doesn't contain "degraded"`},
+		{"regex mismatch", HTTPCheck{URL: server.URL + "/health", BodyRegex: `version": "2\.`}, "body doesn't match"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			check := Check{Service: "web", HTTP: &test.check}
+			if err := check.validate(map[string]struct{}{"web": {}}); err != nil {
+				t.Fatalf("expected check to be valid, got %v", err)
+			}
+
+			result := check.checker().Check(context.Background())
+			if len(test.expected) == 0 {
+				if !result.OK {
+					t.Errorf("expected check to pass, got %q", result.Message)
+				}
+				return
+			}
+			if result.OK || !strings.Contains(result.Message, test.expected) {
+				t.Errorf("expected check to fail with %q, got %+v", test.expected, result)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if result := (&HTTPCheck{URL: server.URL + "/slow"}).Check(ctx); result.OK {
+		t.Error("expected the request to time out")
+	}
+}
+
+func TestCheckValidation(t *testing.T) {
+	services := map[string]struct{}{"web": {}}
+	for _, check := range []Check{
+		{Service: "missing", HTTP: &HTTPCheck{URL: "https://example.com"}},
+		{Service: "web"},
+		{Service: "web", HTTP: &HTTPCheck{URL: "ftp://example.com"}},
+		{Service: "web", HTTP: &HTTPCheck{URL: "https://example.com"}, TCP: &TCPCheck{Address: "example.com:443"}},
+		{Service: "web", TCP: &TCPCheck{Address: "example.com"}},
+		{Service: "web", HTTP: &HTTPCheck{URL: "https://example.com", BodyRegex: "("}},
+		{Service: "web", Interval: -time.Second, HTTP: &HTTPCheck{URL: "https://example.com"}},
+	} {
+		if err := check.validate(services); !errors.Is(err, apperror.ErrInvalidCheck) {
+			t.Errorf("expected ErrInvalidCheck for %+v, got %v", check, err)
+		}
+	}
+
+	cfg := Config{MaxConcurrent: -1}
+	if err := cfg.Validate(services); !errors.Is(err, apperror.ErrInvalidCheck) {
+		t.Errorf("expected ErrInvalidCheck for a negative max_concurrent, got %v", err)
+	}
+}
+
+func TestCheckIDs(t *testing.T) {
+	cfg := Config{Checks: []Check{
+		{Service: "web", HTTP: &HTTPCheck{URL: "https://example.com"}},
+		{Service: "web", TLS: &TLSCheck{Address: "example.com:443"}},
+		{Service: "web", HTTP: &HTTPCheck{URL: "https://example.com/health"}},
+		{Service: "api", HTTP: &HTTPCheck{URL: "https://api.example.com"}},
+	}}
+	if diff := cmp.Diff([]string{"http", "tls", "http#2", "http"}, cfg.IDs()); diff != "" {
+		t.Errorf("check IDs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			// Send the banner in two parts to make sure partial reads are handled.
+			conn.Write([]byte("SSH-2.0-"))
+			time.Sleep(10 * time.Millisecond)
+			conn.Write([]byte("OpenSSH_9.6\r\n"))
+			conn.Close()
+		}
+	}()
+
+	for _, test := range []struct {
+		check TCPCheck
+		ok    bool
+	}{
+		{TCPCheck{Address: listener.Addr().String()}, true},
+		{TCPCheck{Address: listener.Addr().String(), Banner: `^SSH-2\.0-OpenSSH`}, true},
+		{TCPCheck{Address: listener.Addr().String(), Banner: `^220 `}, false},
+	} {
+		check := Check{Service: "db", Timeout: time.Second, TCP: &test.check}
+		if err := check.validate(map[string]struct{}{"db": {}}); err != nil {
+			t.Fatalf("expected check to be valid, got %v", err)
+		}
+		if result := check.checker().Check(context.Background()); result.OK != test.ok {
+			t.Errorf("expected ok to be %v for %+v, got %+v", test.ok, test.check, result)
+		}
+	}
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := closed.Addr().String()
+	closed.Close()
+	check := Check{Service: "db", Timeout: time.Second, TCP: &TCPCheck{Address: address}}
+	if result := check.checker().Check(context.Background()); result.OK {
+		t.Error("expected connecting to a closed port to fail")
+	}
+}
+
+// startDNSStub answers A and TXT queries over UDP from records, keyed by the lowercase name without the trailing dot.
+func startDNSStub(t *testing.T, records map[string][]string) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			query := buf[:n]
+
+			// The question starts after the 12 byte header: labels, then the type and class.
+			var labels []string
+			offset := 12
+			for query[offset] != 0 {
+				length := int(query[offset])
+				labels = append(labels, string(query[offset+1:offset+1+length]))
+				offset += 1 + length
+			}
+			questionEnd := offset + 5
+			qtype := binary.BigEndian.Uint16(query[offset+1:])
+
+			var answers [][]byte
+			for _, record := range records[strings.ToLower(strings.Join(labels, "."))] {
+				var rdata []byte
+				if ip := net.ParseIP(record).To4(); ip != nil && qtype == 1 {
+					rdata = ip
+				} else if ip == nil && qtype == 16 {
+					rdata = append([]byte{byte(len(record))}, record...)
+				} else {
+					continue
+				}
+				answer := []byte{0xc0, 12}
+				answer = binary.BigEndian.AppendUint16(answer, qtype)
+				answer = binary.BigEndian.AppendUint16(answer, 1)
+				answer = binary.BigEndian.AppendUint32(answer, 60)
+				answer = binary.BigEndian.AppendUint16(answer, uint16(len(rdata)))
+				answers = append(answers, append(answer, rdata...))
+			}
+
+			response := slices.Clone(query[:questionEnd])
+			response[2] = 0x81 // response, recursion desired
+			response[3] = 0x80 // recursion available, no error
+			binary.BigEndian.PutUint16(response[6:], uint16(len(answers)))
+			binary.BigEndian.PutUint16(response[8:], 0)
+			binary.BigEndian.PutUint16(response[10:], 0)
+			for _, answer := range answers {
+				response = append(response, answer...)
+			}
+			conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDNSCheck(t *testing.T) {
+	resolver := startDNSStub(t, map[string][]string{
+		"db.internal": {"10.0.0.5", "10.0.0.6"},
+		"internal":    {"v=spf1 -all"},
+	})
+
+	for _, test := range []struct {
+		check DNSCheck
+		ok    bool
+	}{
+		{DNSCheck{Name: "db.internal.", Resolver: resolver}, true},
+		{DNSCheck{Name: "db.internal.", Resolver: resolver, Expected: []string{"10.0.0.6"}}, true},
+		{DNSCheck{Name: "db.internal.", Resolver: resolver, Expected: []string{"10.0.0.7"}}, false},
+		{DNSCheck{Name: "internal.", Type: "txt", Resolver: resolver, Expected: []string{"v=spf1 -all"}}, true},
+		{DNSCheck{Name: "missing.internal.", Resolver: resolver}, false},
+	} {
+		check := Check{Service: "dns", Timeout: time.Second, DNS: &test.check}
+		if err := check.validate(map[string]struct{}{"dns": {}}); err != nil {
+			t.Fatalf("expected check to be valid, got %v", err)
+		}
+		if result := check.checker().Check(context.Background()); result.OK != test.ok {
+			t.Errorf("expected ok to be %v for %+v, got %+v", test.ok, test.check, result)
+		}
+	}
+
+	for _, check := range []DNSCheck{
+		{},
+		{Name: "db.internal", Type: "SRV"},
+		{Name: "db.internal", Resolver: "10.0.0.1"},
+	} {
+		if err := (&Check{Service: "dns", DNS: &check}).validate(map[string]struct{}{"dns": {}}); !errors.Is(err, apperror.ErrInvalidCheck) {
+			t.Errorf("expected ErrInvalidCheck for %+v, got %v", check, err)
+		}
+	}
+}
+
+// writeCertificate writes a self-signed certificate for example.com valid between notBefore and notAfter.
+func writeCertificate(t *testing.T, notBefore, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "example.com"},
+		DNSNames:              []string{"example.com"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	return path
+}
+
+func TestTLSCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+	address := server.Listener.Addr().String()
+
+	now := time.Now()
+	expiringSoon := writeCertificate(t, now.Add(-time.Hour), now.Add(10*24*time.Hour))
+	expired := writeCertificate(t, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+
+	for _, test := range []struct {
+		name    string
+		check   TLSCheck
+		ok      bool
+		message string
+	}{
+		{"valid endpoint", TLSCheck{Address: address, ServerName: "example.com", CAFile: caFile}, true, ""},
+		{"untrusted endpoint", TLSCheck{Address: address, ServerName: "example.com"}, false, "certificate verification failed"},
+		{"hostname mismatch", TLSCheck{Address: address, ServerName: "other.com", CAFile: caFile}, false, "certificate verification failed"},
+		{"expiring file", TLSCheck{File: expiringSoon, ServerName: "example.com", CAFile: expiringSoon}, true, "expires within 14 days"},
+		{"custom thresholds", TLSCheck{File: expiringSoon, CAFile: expiringSoon, WarnDays: []int{60, 5}}, true, "expires within 60 days"},
+		{"expired file", TLSCheck{File: expired, CAFile: expired}, false, "certificate example.com expired on"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			check := Check{Service: "web", Timeout: time.Second, TLS: &test.check}
+			if err := check.validate(map[string]struct{}{"web": {}}); err != nil {
+				t.Fatalf("expected check to be valid, got %v", err)
+			}
+
+			result := check.checker().Check(context.Background())
+			if result.OK != test.ok || !strings.Contains(result.Message, test.message) || (len(test.message) == 0 && len(result.Message) != 0) ||
+				result.Warning != (test.ok && len(test.message) != 0) {
+				t.Errorf("expected ok %v with message %q, got %+v", test.ok, test.message, result)
+			}
+		})
+	}
+
+	for _, check := range []TLSCheck{
+		{},
+		{Address: address, File: expired},
+		{Address: address, WarnDays: []int{0}},
+		{Address: address, CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	} {
+		if err := (&Check{Service: "web", TLS: &check}).validate(map[string]struct{}{"web": {}}); !errors.Is(err, apperror.ErrInvalidCheck) {
+			t.Errorf("expected ErrInvalidCheck for %+v, got %v", check, err)
+		}
+	}
+}
+
+func TestFileCheck(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for _, file := range []struct {
+		name    string
+		size    int
+		modTime time.Time
+	}{
+		{"backup-1.tar", 2048, now.Add(-48 * time.Hour)},
+		{"backup-2.tar", 10, now.Add(-time.Hour)},
+		{"notes.txt", 2048, now},
+	} {
+		path := filepath.Join(dir, file.name)
+		if err := os.WriteFile(path, make([]byte, file.size), 0o600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		os.Chtimes(path, file.modTime, file.modTime)
+	}
+
+	for _, test := range []struct {
+		name    string
+		check   FileCheck
+		message string
+	}{
+		{"fresh file", FileCheck{Path: filepath.Join(dir, "backup-2.tar"), MaxAge: 2 * time.Hour}, ""},
+		{"stale file", FileCheck{Path: filepath.Join(dir, "backup-1.tar"), MaxAge: 24 * time.Hour}, "more than the maximum of 24h0m0s"},
+		{"missing file", FileCheck{Path: filepath.Join(dir, "missing"), MaxAge: time.Hour}, "no such file"},
+		{"newest in directory", FileCheck{Path: dir, MaxAge: time.Minute}, ""},
+		{"newest matching pattern", FileCheck{Path: dir, Pattern: "backup-*.tar", MaxAge: 2 * time.Hour}, ""},
+		{"too small", FileCheck{Path: dir, Pattern: "backup-*.tar", MaxAge: 2 * time.Hour, MinSize: 1024}, "backup-2.tar is 10 bytes"},
+		{"nothing matching", FileCheck{Path: dir, Pattern: "*.zip", MaxAge: time.Hour}, `no files matching "*.zip"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			check := Check{Service: "restic", File: &test.check}
+			if err := check.validate(map[string]struct{}{"restic": {}}); err != nil {
+				t.Fatalf("expected check to be valid, got %v", err)
+			}
+
+			result := check.checker().Check(context.Background())
+			if len(test.message) == 0 {
+				if !result.OK {
+					t.Errorf("expected check to pass, got %q", result.Message)
+				}
+				return
+			}
+			if result.OK || !strings.Contains(result.Message, test.message) {
+				t.Errorf("expected check to fail with %q, got %+v", test.message, result)
+			}
+		})
+	}
+
+	for _, check := range []FileCheck{
+		{MaxAge: time.Hour},
+		{Path: dir},
+		{Path: dir, MaxAge: time.Hour, Pattern: "["},
+		{Path: dir, MaxAge: time.Hour, MinSize: -1},
+	} {
+		if err := (&Check{Service: "restic", File: &check}).validate(map[string]struct{}{"restic": {}}); !errors.Is(err, apperror.ErrInvalidCheck) {
+			t.Errorf("expected ErrInvalidCheck for %+v, got %v", check, err)
+		}
+	}
+}
+
+func TestCommandCheck(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		script  string
+		ok      bool
+		warning bool
+		message string
+		metrics map[string]float64
+	}{
+		{
+			name:    "ok",
+			script:  "echo 'DISK OK - free space: / 3326 MB (56%) | /=2643MB;5948;5958;0;5968'",
+			ok:      true,
+			message: "DISK OK - free space: / 3326 MB (56%)",
+			metrics: map[string]float64{"/": 2643},
+		},
+		{
+			name:    "warning",
+			script:  "echo 'LOAD WARNING - load average: 5.2 | load1=5.2;5;10 load5=3.1;4;8'; exit 1",
+			ok:      true,
+			warning: true,
+			message: "LOAD WARNING - load average: 5.2",
+			metrics: map[string]float64{"load1": 5.2, "load5": 3.1},
+		},
+		{
+			name:    "critical with long output",
+			script:  "printf 'PROCS CRITICAL - 0 processes\\nno rsyncd running | procs=0;;1:\\n'; exit 2",
+			message: "PROCS CRITICAL - 0 processes",
+			metrics: map[string]float64{"procs": 0},
+		},
+		{
+			name:    "unknown",
+			script:  "echo 'invalid argument'; exit 3",
+			message: "UNKNOWN: invalid argument",
+		},
+		{
+			name:    "unexpected exit code",
+			script:  "exit 127",
+			message: "sh exited with 127 without output",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			check := Check{Service: "backup", Command: &CommandCheck{Command: []string{"sh", "-c", test.script}}}
+			if err := check.validate(map[string]struct{}{"backup": {}}); err != nil {
+				t.Fatalf("expected check to be valid, got %v", err)
+			}
+
+			result := check.checker().Check(context.Background())
+			if result.OK != test.ok || result.Warning != test.warning || result.Message != test.message {
+				t.Errorf("expected ok %t, warning %t with %q, got %+v", test.ok, test.warning, test.message, result)
+			}
+			if diff := cmp.Diff(test.metrics, result.Metrics); diff != "" {
+				t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if result := (&CommandCheck{Command: []string{"sleep", "1"}}).Check(ctx); result.OK || !strings.Contains(result.Message, "timed out") {
+		t.Errorf("expected check to time out, got %+v", result)
+	}
+
+	// The shell's child keeps the output open unless it's killed along with the shell.
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	result := (&CommandCheck{Command: []string{"sh", "-c", "sleep 5; echo done"}}).Check(ctx)
+	if result.OK || !strings.Contains(result.Message, "timed out") || result.Latency > 2*time.Second {
+		t.Errorf("expected check to time out promptly, got %+v", result)
+	}
+
+	for _, check := range []CommandCheck{{}, {Command: []string{"/does/not/exist"}}} {
+		if err := (&Check{Service: "backup", Command: &check}).validate(map[string]struct{}{"backup": {}}); !errors.Is(err, apperror.ErrInvalidCheck) {
+			t.Errorf("expected ErrInvalidCheck for %+v, got %v", check, err)
+		}
+	}
+}
+
+func TestParsePerfdata(t *testing.T) {
+	metrics := parsePerfdata(`'free space'=12.5GB;;;0;100 time=0.02s size=U broken 'rta'=-1.5ms`)
+	expected := map[string]float64{"free space": 12.5, "time": 0.02, "rta": -1.5}
+	if diff := cmp.Diff(expected, metrics); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// fakeChecker takes a while to check and tracks how many checks run at the same time.
+type fakeChecker struct {
+	duration time.Duration
+	running  *atomic.Int32
+	peak     *atomic.Int32
+}
+
+func (f *fakeChecker) Validate() error {
+	return nil
+}
+
+func (f *fakeChecker) Check(ctx context.Context) Result {
+	running := f.running.Add(1)
+	defer f.running.Add(-1)
+	for peak := f.peak.Load(); running > peak && !f.peak.CompareAndSwap(peak, running); peak = f.peak.Load() {
+	}
+
+	select {
+	case <-time.After(f.duration):
+		return Result{OK: true}
+	case <-ctx.Done():
+		return Result{Message: ctx.Err().Error()}
+	}
+}
+
+type recordingReporter struct {
+	mutex   sync.Mutex
+	results map[string][]string
+}
+
+func (r *recordingReporter) ReportCheckResult(service string, _ string, ok bool, _ bool, message string, _ map[string]float64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.results == nil {
+		r.results = make(map[string][]string)
+	}
+	if ok {
+		message = "ok"
+	}
+	r.results[service] = append(r.results[service], message)
+	return nil
+}
+
+func TestScheduler(t *testing.T) {
+	var running, peak atomic.Int32
+	reporter := &recordingReporter{}
+	scheduler := &Scheduler{maxConcurrent: 2, reporter: reporter, random: func(time.Duration) time.Duration { return 0 }}
+	for _, service := range []string{"a", "b", "c", "d"} {
+		scheduler.jobs = append(scheduler.jobs, &job{
+			service:  service,
+			checker:  &fakeChecker{duration: 20 * time.Millisecond, running: &running, peak: &peak},
+			interval: 10 * time.Millisecond,
+			timeout:  time.Second,
+		})
+	}
+	scheduler.jobs = append(scheduler.jobs, &job{
+		service:  "slow",
+		checker:  &fakeChecker{duration: time.Second, running: &running, peak: &peak},
+		interval: 10 * time.Millisecond,
+		timeout:  30 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	scheduler.Run(ctx)
+
+	if running.Load() != 0 {
+		t.Error("expected running checks to finish before Run returns")
+	}
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent checks, got %d", peak.Load())
+	}
+	for _, service := range []string{"a", "b", "c", "d"} {
+		if results := reporter.results[service]; len(results) < 2 || slices.ContainsFunc(results, func(result string) bool { return result != "ok" }) {
+			t.Errorf("expected %s to be checked repeatedly, got %v", service, results)
+		}
+	}
+	if results := reporter.results["slow"]; len(results) == 0 || results[0] != context.DeadlineExceeded.Error() {
+		t.Errorf("expected the slow check to time out, got %v", results)
+	}
+}
+
+func TestSchedulerDelay(t *testing.T) {
+	scheduler := &Scheduler{random: func(d time.Duration) time.Duration { return d - 1 }}
+	if delay := scheduler.delay(time.Minute); delay < 54*time.Second || delay > 66*time.Second {
+		t.Errorf("expected the delay to stay within 10%% of the interval, got %s", delay)
+	}
+	scheduler.random = func(time.Duration) time.Duration { return 0 }
+	if delay := scheduler.delay(time.Minute); delay != 54*time.Second {
+		t.Errorf("expected the minimal delay to be 54s, got %s", delay)
+	}
+}
@@ -0,0 +1,158 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Exit codes of Nagios compatible plugins.
+const (
+	exitOK       = 0
+	exitWarning  = 1
+	exitCritical = 2
+	exitUnknown  = 3
+)
+
+// commandWaitDelay bounds how long the output of a timed out plugin is waited for once it has been killed.
+const commandWaitDelay = time.Second
+
+// CommandCheck runs a Nagios compatible plugin, Command being the executable followed by its arguments. The exit
+// code decides the result: OK passes, WARNING passes with the output as warning, CRITICAL and UNKNOWN fail. The
+// first line of the output is the message of the result in any case, its performance data is reported as metrics.
+type CommandCheck struct {
+	Command []string `yaml:"command"`
+}
+
+func (c *CommandCheck) Validate() error {
+	if len(c.Command) == 0 || len(c.Command[0]) == 0 {
+		return errors.New("command is required")
+	}
+	if _, err := exec.LookPath(c.Command[0]); err != nil {
+		return fmt.Errorf("command: %w", err)
+	}
+	return nil
+}
+
+func (c *CommandCheck) Check(ctx context.Context) Result {
+	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
+	// Plugins often run through a shell, the children they start are killed along with them on timeout so they
+	// don't keep the output open.
+	killProcessGroup(cmd)
+	cmd.WaitDelay = commandWaitDelay
+	start := time.Now()
+	output, err := cmd.Output()
+	latency := time.Since(start)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Result{Message: fmt.Sprintf("UNKNOWN: %s timed out", c.Command[0]), Latency: latency}
+	}
+	if ctx.Err() != nil {
+		return Result{Message: fmt.Sprintf("UNKNOWN: %s: %v", c.Command[0], ctx.Err()), Latency: latency}
+	}
+
+	code := exitOK
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return Result{Message: fmt.Sprintf("UNKNOWN: %v", err), Latency: latency}
+		}
+		code = exitErr.ExitCode()
+	}
+
+	text, metrics := parsePluginOutput(string(output))
+	result := Result{Latency: latency, Metrics: metrics}
+	switch code {
+	case exitOK:
+		result.OK = true
+		result.Message = text
+	case exitWarning:
+		result.OK = true
+		result.Warning = true
+		result.Message = text
+	case exitCritical:
+		result.Message = text
+	case exitUnknown:
+		result.Message = "UNKNOWN: " + text
+	default:
+		result.Message = fmt.Sprintf("UNKNOWN: %s exited with %d: %s", c.Command[0], code, text)
+	}
+	if !result.OK && len(text) == 0 {
+		result.Message = fmt.Sprintf("%s exited with %d without output", c.Command[0], code)
+	}
+	return result
+}
+
+// parsePluginOutput splits the output of a plugin into its status text and performance data, which follows a pipe
+// on the first line and, for multi-line output, after the first pipe in the remaining lines:
+//
+//	DISK OK - free space: / 3326 MB (56%) | /=2643MB;5948;5958;0;5968
+func parsePluginOutput(output string) (string, map[string]float64) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	text, perfdata, _ := strings.Cut(lines[0], "|")
+	if len(lines) > 1 {
+		_, rest, found := strings.Cut(strings.Join(lines[1:], "\n"), "|")
+		if found {
+			perfdata += " " + rest
+		}
+	}
+	return strings.TrimSpace(text), parsePerfdata(perfdata)
+}
+
+// parsePerfdata parses space separated 'label'=value[UOM];[warn];[crit];[min];[max] entries into a map of labels to
+// values, the unit of measurement and thresholds are dropped. Malformed entries are skipped.
+func parsePerfdata(perfdata string) map[string]float64 {
+	var metrics map[string]float64
+	for _, entry := range splitPerfdata(perfdata) {
+		var label, value string
+		if quoted, found := strings.CutPrefix(entry, "'"); found {
+			label, value, found = strings.Cut(quoted, "'=")
+			if !found {
+				continue
+			}
+		} else if label, value, found = strings.Cut(entry, "="); !found {
+			continue
+		}
+
+		value, _, _ = strings.Cut(value, ";")
+		value = strings.TrimRightFunc(value, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		})
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || len(label) == 0 {
+			continue
+		}
+		if metrics == nil {
+			metrics = make(map[string]float64)
+		}
+		metrics[label] = number
+	}
+	return metrics
+}
+
+// splitPerfdata splits performance data at whitespace outside of quoted labels.
+func splitPerfdata(perfdata string) []string {
+	var entries []string
+	var entry strings.Builder
+	quoted := false
+	for _, r := range perfdata {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if entry.Len() != 0 {
+				entries = append(entries, entry.String())
+				entry.Reset()
+			}
+			continue
+		}
+		entry.WriteRune(r)
+	}
+	if entry.Len() != 0 {
+		entries = append(entries, entry.String())
+	}
+	return entries
+}
@@ -0,0 +1,139 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+var dnsRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "TXT"}
+
+// DNSCheck resolves Name and expects every record in Expected among the answers, or any answer if Expected is
+// empty. Resolver is the host:port of the DNS server to ask, the system resolver is used if it's left empty.
+type DNSCheck struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"`
+	Resolver string   `yaml:"resolver"`
+	Expected []string `yaml:"expected"`
+}
+
+func (d *DNSCheck) Validate() error {
+	if len(d.Name) == 0 {
+		return errors.New("name is required")
+	}
+	if !slices.Contains(dnsRecordTypes, d.recordType()) {
+		return fmt.Errorf("unsupported record type %q, must be one of %s", d.Type, strings.Join(dnsRecordTypes, ", "))
+	}
+	if len(d.Resolver) != 0 {
+		if _, _, err := net.SplitHostPort(d.Resolver); err != nil {
+			return fmt.Errorf("resolver: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *DNSCheck) recordType() string {
+	if len(d.Type) == 0 {
+		return "A"
+	}
+	return strings.ToUpper(d.Type)
+}
+
+func (d *DNSCheck) resolver() *net.Resolver {
+	if len(d.Resolver) == 0 {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, d.Resolver)
+		},
+	}
+}
+
+func (d *DNSCheck) lookup(ctx context.Context) ([]string, error) {
+	resolver := d.resolver()
+	var records []string
+	switch d.recordType() {
+	case "A", "AAAA":
+		network := "ip4"
+		if d.recordType() == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, d.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, d.Name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, d.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, mx.Host)
+		}
+	case "NS":
+		nss, err := resolver.LookupNS(ctx, d.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			records = append(records, ns.Host)
+		}
+	case "TXT":
+		return resolver.LookupTXT(ctx, d.Name)
+	}
+	return records, nil
+}
+
+// normalize makes records comparable regardless of how they are written, e.g. with or without the trailing dot.
+func (d *DNSCheck) normalize(record string) string {
+	if d.recordType() == "TXT" {
+		return record
+	}
+	if ip := net.ParseIP(record); ip != nil {
+		return ip.String()
+	}
+	return strings.TrimSuffix(strings.ToLower(record), ".")
+}
+
+func (d *DNSCheck) Check(ctx context.Context) Result {
+	start := time.Now()
+	records, err := d.lookup(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Message: err.Error(), Latency: latency}
+	}
+	if len(records) == 0 {
+		return Result{Message: fmt.Sprintf("no %s records for %s", d.recordType(), d.Name), Latency: latency}
+	}
+
+	for i := range records {
+		records[i] = d.normalize(records[i])
+	}
+	for _, expected := range d.Expected {
+		if !slices.Contains(records, d.normalize(expected)) {
+			return Result{
+				Message: fmt.Sprintf("%s record %s missing for %s, got %s", d.recordType(), expected, d.Name, strings.Join(records, ", ")),
+				Latency: latency,
+			}
+		}
+	}
+
+	return Result{OK: true, Latency: latency}
+}
@@ -0,0 +1,95 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileCheck expects the file at Path to have been modified within MaxAge and to be at least MinSize bytes. If Path
+// is a directory the newest file in it matching Pattern, in the syntax of filepath.Match, is checked instead.
+type FileCheck struct {
+	Path    string        `yaml:"path"`
+	Pattern string        `yaml:"pattern"`
+	MaxAge  time.Duration `yaml:"max_age"`
+	MinSize int64         `yaml:"min_size"`
+}
+
+func (f *FileCheck) Validate() error {
+	if len(f.Path) == 0 {
+		return errors.New("path is required")
+	}
+	if f.MaxAge <= 0 {
+		return errors.New("max_age must be positive")
+	}
+	if f.MinSize < 0 {
+		return errors.New("min_size can't be negative")
+	}
+	if _, err := filepath.Match(f.pattern(), ""); err != nil {
+		return fmt.Errorf("pattern: %w", err)
+	}
+	return nil
+}
+
+func (f *FileCheck) pattern() string {
+	if len(f.Pattern) == 0 {
+		return "*"
+	}
+	return f.Pattern
+}
+
+// newest returns the path and info of the file to check.
+func (f *FileCheck) newest() (string, fs.FileInfo, error) {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	if !info.IsDir() {
+		return f.Path, info, nil
+	}
+
+	entries, err := os.ReadDir(f.Path)
+	if err != nil {
+		return "", nil, err
+	}
+	var newestPath string
+	var newest fs.FileInfo
+	for _, entry := range entries {
+		if matched, _ := filepath.Match(f.pattern(), entry.Name()); !matched || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if newest == nil || info.ModTime().After(newest.ModTime()) {
+			newestPath, newest = filepath.Join(f.Path, entry.Name()), info
+		}
+	}
+	if newest == nil {
+		return "", nil, fmt.Errorf("no files matching %q in %s", f.pattern(), f.Path)
+	}
+	return newestPath, newest, nil
+}
+
+func (f *FileCheck) Check(context.Context) Result {
+	start := time.Now()
+	path, info, err := f.newest()
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Message: err.Error(), Latency: latency}
+	}
+
+	if age := time.Since(info.ModTime()); age > f.MaxAge {
+		return Result{Message: fmt.Sprintf("%s was last modified %s ago, more than the maximum of %s", path, age.Round(time.Second), f.MaxAge), Latency: latency}
+	}
+	if info.Size() < f.MinSize {
+		return Result{Message: fmt.Sprintf("%s is %d bytes, less than the minimum of %d", path, info.Size(), f.MinSize), Latency: latency}
+	}
+
+	return Result{OK: true, Latency: latency}
+}
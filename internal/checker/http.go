@@ -0,0 +1,89 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxBodySize limits how much of the response is read for the body checks.
+const maxBodySize = 1 << 20
+
+// HTTPCheck requests URL and expects one of ExpectedStatus (any 2xx if empty) within MaxLatency, with a body
+// containing BodyContains and matching BodyRegex when set.
+type HTTPCheck struct {
+	URL            string        `yaml:"url"`
+	Method         string        `yaml:"method"`
+	ExpectedStatus []int         `yaml:"expected_status"`
+	MaxLatency     time.Duration `yaml:"max_latency"`
+	BodyContains   string        `yaml:"body_contains"`
+	BodyRegex      string        `yaml:"body_regex"`
+	bodyRegex      *regexp.Regexp
+}
+
+func (h *HTTPCheck) Validate() error {
+	parsed, err := url.Parse(h.URL)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("url must use http or https: %q", h.URL)
+	}
+	if h.MaxLatency < 0 {
+		return errors.New("max_latency can't be negative")
+	}
+	if len(h.BodyRegex) != 0 {
+		if h.bodyRegex, err = regexp.Compile(h.BodyRegex); err != nil {
+			return fmt.Errorf("body_regex: %w", err)
+		}
+	}
+	return nil
+}
+
+func (h *HTTPCheck) method() string {
+	if len(h.Method) == 0 {
+		return http.MethodGet
+	}
+	return strings.ToUpper(h.Method)
+}
+
+func (h *HTTPCheck) Check(ctx context.Context) Result {
+	request, err := http.NewRequestWithContext(ctx, h.method(), h.URL, nil)
+	if err != nil {
+		return Result{Message: err.Error()}
+	}
+
+	start := time.Now()
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return Result{Message: err.Error(), Latency: time.Since(start)}
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxBodySize))
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Message: fmt.Sprintf("failed to read body: %v", err), Latency: latency}
+	}
+
+	switch {
+	case len(h.ExpectedStatus) == 0 && (response.StatusCode < 200 || response.StatusCode > 299),
+		len(h.ExpectedStatus) != 0 && !slices.Contains(h.ExpectedStatus, response.StatusCode):
+		return Result{Message: fmt.Sprintf("unexpected status %d", response.StatusCode), Latency: latency}
+	case h.MaxLatency > 0 && latency > h.MaxLatency:
+		return Result{Message: fmt.Sprintf("took %s, more than the maximum of %s", latency.Round(time.Millisecond), h.MaxLatency), Latency: latency}
+	case len(h.BodyContains) != 0 && !strings.Contains(string(body), h.BodyContains):
+		return Result{Message: fmt.Sprintf("body doesn't contain %q", h.BodyContains), Latency: latency}
+	case h.bodyRegex != nil && !h.bodyRegex.Match(body):
+		return Result{Message: fmt.Sprintf("body doesn't match %q", h.BodyRegex), Latency: latency}
+	}
+
+	return Result{OK: true, Latency: latency}
+}
@@ -0,0 +1,143 @@
+package checker
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// jitterFraction is how far a run may deviate from the interval of its check, so checks sharing an interval drift
+// apart instead of running in bursts.
+const jitterFraction = 0.1
+
+// job is a check as seen by the scheduler.
+type job struct {
+	service  string
+	id       string
+	checker  Checker
+	interval time.Duration
+	timeout  time.Duration
+	next     time.Time
+}
+
+// Scheduler runs checks on their intervals, at most maxConcurrent at a time, and feeds the results to the reporter.
+// A check is never run again before its previous run finished.
+type Scheduler struct {
+	jobs          []*job
+	maxConcurrent int
+	reporter      Reporter
+	// random returns a random duration in [0, d), it's replaced in tests.
+	random func(d time.Duration) time.Duration
+}
+
+func NewScheduler(cfg *Config, reporter Reporter) *Scheduler {
+	jobs := make([]*job, 0, len(cfg.Checks))
+	for i, id := range cfg.IDs() {
+		check := &cfg.Checks[i]
+		jobs = append(jobs, &job{
+			service:  check.Service,
+			id:       id,
+			checker:  check.checker(),
+			interval: check.interval(),
+			timeout:  check.timeout(),
+		})
+	}
+
+	return &Scheduler{
+		jobs:          jobs,
+		maxConcurrent: cfg.maxConcurrent(),
+		reporter:      reporter,
+		random: func(d time.Duration) time.Duration {
+			if d <= 0 {
+				return 0
+			}
+			return rand.N(d)
+		},
+	}
+}
+
+// delay returns the time until the next run of a check that just finished.
+func (s *Scheduler) delay(interval time.Duration) time.Duration {
+	jitter := time.Duration(float64(interval) * jitterFraction)
+	return interval - jitter + s.random(2*jitter)
+}
+
+// Run schedules the checks until ctx is done, then waits for the running checks to finish.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+
+	// The first runs are spread over the interval so checks don't all start at once.
+	now := time.Now()
+	for _, job := range s.jobs {
+		job.next = now.Add(s.random(job.interval))
+	}
+
+	pending := slices.Clone(s.jobs)
+	done := make(chan *job, len(s.jobs))
+	slots := make(chan struct{}, s.maxConcurrent)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		var next *job
+		for _, job := range pending {
+			if next == nil || job.next.Before(next.next) {
+				next = job
+			}
+		}
+		var due <-chan time.Time
+		if next != nil {
+			timer.Reset(time.Until(next.next))
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-done:
+			job.next = time.Now().Add(s.delay(job.interval))
+			pending = append(pending, job)
+		case <-due:
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			pending = slices.DeleteFunc(pending, func(job *job) bool { return job == next })
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.run(ctx, next)
+				<-slots
+				done <- next
+			}()
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job *job) {
+	checkCtx, cancel := context.WithTimeout(ctx, job.timeout)
+	result := job.checker.Check(checkCtx)
+	cancel()
+	if ctx.Err() != nil {
+		// Shutting down, the result says nothing about the service.
+		return
+	}
+
+	switch {
+	case !result.OK:
+		slog.Warn("Check failed", "service", job.service, "check", job.id, "reason", result.Message, "latency", result.Latency)
+	case result.Warning:
+		slog.Warn("Check passed with a warning", "service", job.service, "check", job.id, "warning", result.Message)
+	}
+	if err := s.reporter.ReportCheckResult(job.service, job.id, result.OK, result.Warning, result.Message, result.Metrics); err != nil {
+		slog.Error("Failed to report check result", "service", job.service, "check", job.id, "error", err)
+	}
+}
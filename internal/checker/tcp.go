@@ -0,0 +1,65 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+)
+
+// maxBannerSize limits how much is read from the connection while waiting for the banner.
+const maxBannerSize = 4096
+
+// TCPCheck connects to Address, a host:port pair, and expects the server to send something matching the Banner
+// regular expression when set, e.g. "^SSH-2\\.0" or "^220 ".
+type TCPCheck struct {
+	Address string `yaml:"address"`
+	Banner  string `yaml:"banner"`
+	banner  *regexp.Regexp
+}
+
+func (t *TCPCheck) Validate() error {
+	if _, _, err := net.SplitHostPort(t.Address); err != nil {
+		return fmt.Errorf("address: %w", err)
+	}
+	if len(t.Banner) != 0 {
+		var err error
+		if t.banner, err = regexp.Compile(t.Banner); err != nil {
+			return fmt.Errorf("banner: %w", err)
+		}
+	}
+	return nil
+}
+
+func (t *TCPCheck) Check(ctx context.Context) Result {
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", t.Address)
+	if err != nil {
+		return Result{Message: err.Error(), Latency: time.Since(start)}
+	}
+	defer conn.Close()
+
+	if t.banner == nil {
+		return Result{OK: true, Latency: time.Since(start)}
+	}
+
+	// The banner may arrive in several reads, keep reading until it matches, the server is done or time is up.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+	}
+	buf := make([]byte, 0, maxBannerSize)
+	for len(buf) < maxBannerSize {
+		n, err := conn.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if t.banner.Match(buf) {
+			return Result{OK: true, Latency: time.Since(start)}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return Result{Message: fmt.Sprintf("banner %q doesn't match %q", buf, t.Banner), Latency: time.Since(start)}
+}
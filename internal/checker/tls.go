@@ -0,0 +1,170 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"time"
+)
+
+var defaultWarnDays = []int{30, 14, 7}
+
+// TLSCheck inspects the certificate chain served at Address, a host:port pair, or stored in File as PEM. Expired
+// certificates and chains that don't verify for ServerName fail the check, certificates expiring within one of
+// WarnDays raise a warning. CAFile adds trusted roots on top of the system ones, e.g. for an internal CA.
+type TLSCheck struct {
+	Address    string `yaml:"address"`
+	File       string `yaml:"file"`
+	ServerName string `yaml:"server_name"`
+	CAFile     string `yaml:"ca_file"`
+	WarnDays   []int  `yaml:"warn_days"`
+}
+
+func (t *TLSCheck) Validate() error {
+	if (len(t.Address) == 0) == (len(t.File) == 0) {
+		return errors.New("exactly one of address and file is required")
+	}
+	if len(t.Address) != 0 {
+		if _, _, err := net.SplitHostPort(t.Address); err != nil {
+			return fmt.Errorf("address: %w", err)
+		}
+	}
+	if slices.ContainsFunc(t.WarnDays, func(days int) bool { return days <= 0 }) {
+		return errors.New("warn_days must be positive")
+	}
+	if len(t.CAFile) != 0 {
+		if _, err := t.roots(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TLSCheck) warnDays() []int {
+	if len(t.WarnDays) == 0 {
+		return defaultWarnDays
+	}
+	return t.WarnDays
+}
+
+func (t *TLSCheck) serverName() string {
+	if len(t.ServerName) != 0 || len(t.Address) == 0 {
+		return t.ServerName
+	}
+	host, _, _ := net.SplitHostPort(t.Address)
+	return host
+}
+
+// roots returns the system roots extended by CAFile, nil means the system roots.
+func (t *TLSCheck) roots() (*x509.CertPool, error) {
+	if len(t.CAFile) == 0 {
+		return nil, nil
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	data, err := os.ReadFile(t.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("ca_file: %w", err)
+	}
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("ca_file: no certificates found in %s", t.CAFile)
+	}
+	return roots, nil
+}
+
+// chain fetches the certificates, leaf first.
+func (t *TLSCheck) chain(ctx context.Context) ([]*x509.Certificate, error) {
+	if len(t.File) == 0 {
+		// Verification is done afterwards so expiry can be told apart from other verification errors.
+		dialer := tls.Dialer{Config: &tls.Config{ServerName: t.serverName(), InsecureSkipVerify: true}}
+		conn, err := dialer.DialContext(ctx, "tcp", t.Address)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		return conn.(*tls.Conn).ConnectionState().PeerCertificates, nil
+	}
+
+	data, err := os.ReadFile(t.File)
+	if err != nil {
+		return nil, err
+	}
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, cert)
+	}
+	return chain, nil
+}
+
+func (t *TLSCheck) Check(ctx context.Context) Result {
+	start := time.Now()
+	chain, err := t.chain(ctx)
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Message: err.Error(), Latency: latency}
+	}
+	if len(chain) == 0 {
+		return Result{Message: "no certificates found", Latency: latency}
+	}
+
+	now := time.Now()
+	expiring := chain[0]
+	for _, cert := range chain {
+		if now.Before(cert.NotBefore) {
+			return Result{Message: fmt.Sprintf("certificate %s is not valid before %s", cert.Subject.CommonName, cert.NotBefore.Format(time.DateOnly)), Latency: latency}
+		}
+		if cert.NotAfter.Before(expiring.NotAfter) {
+			expiring = cert
+		}
+	}
+	if now.After(expiring.NotAfter) {
+		return Result{Message: fmt.Sprintf("certificate %s expired on %s", expiring.Subject.CommonName, expiring.NotAfter.Format(time.DateOnly)), Latency: latency}
+	}
+
+	roots, err := t.roots()
+	if err != nil {
+		return Result{Message: err.Error(), Latency: latency}
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := chain[0].Verify(x509.VerifyOptions{DNSName: t.serverName(), Roots: roots, Intermediates: intermediates}); err != nil {
+		return Result{Message: fmt.Sprintf("certificate verification failed: %v", err), Latency: latency}
+	}
+
+	// Warn about the smallest threshold that has been crossed, so each threshold is reported once.
+	remaining := expiring.NotAfter.Sub(now)
+	var crossed int
+	for _, days := range t.warnDays() {
+		if remaining <= time.Duration(days)*24*time.Hour && (crossed == 0 || days < crossed) {
+			crossed = days
+		}
+	}
+	if crossed != 0 {
+		return Result{
+			OK:      true,
+			Warning: true,
+			Message: fmt.Sprintf("certificate %s expires within %d days, on %s", expiring.Subject.CommonName, crossed, expiring.NotAfter.Format(time.DateOnly)),
+			Latency: latency,
+		}
+	}
+
+	return Result{OK: true, Latency: latency}
+}
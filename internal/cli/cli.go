@@ -13,6 +13,8 @@ type CliArgs struct {
 	PwFilePath string
 	ConfigPath string
 	Port       uint16
+	// WatchConfig reloads the config whenever it or one of the secret files it references changes.
+	WatchConfig bool
 }
 
 func ParseArgs() *CliArgs {
@@ -20,6 +22,7 @@ func ParseArgs() *CliArgs {
 	pwFilePath := flag.String("pw-file", "", "Path to the password file, if run without a password file, auth token middleware will be disabled.")
 
 	portFlag := flag.Uint64("port", 8080, "The port that the HTTP server will listen on")
+	watchConfig := flag.Bool("watch-config", false, "Reload the configuration whenever the configuration file or one of the password/token files it references changes")
 	flag.Parse()
 
 	if *portFlag > math.MaxUint16 {
@@ -28,8 +31,9 @@ func ParseArgs() *CliArgs {
 	}
 
 	return &CliArgs{
-		PwFilePath: *pwFilePath,
-		ConfigPath: *configPath,
-		Port:       uint16(*portFlag),
+		PwFilePath:  *pwFilePath,
+		ConfigPath:  *configPath,
+		Port:        uint16(*portFlag),
+		WatchConfig: *watchConfig,
 	}
 }
@@ -0,0 +1,163 @@
+// Package clock abstracts the passing of time, so monitoring can be tested without waiting for real timeouts.
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and creates timers.
+type Clock interface {
+	Now() time.Time
+	// After sends the time on the returned channel once d has passed.
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the subset of time.Timer shared by the real and the fake clock.
+type Timer interface {
+	C() <-chan time.Time
+	Reset(d time.Duration) bool
+	Stop() bool
+}
+
+// Real is the wall clock.
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (Real) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// Fake only moves when told to, timers fire once Advance or Set moves the time past their deadline.
+type Fake struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	// reset is closed and replaced whenever a timer is set, see WaitForTimer.
+	reset chan struct{}
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, reset: make(chan struct{})}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	timer := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	timer.Reset(d)
+	return timer
+}
+
+// Advance moves the time forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the time to now and fires the timers that are due.
+func (f *Fake) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = now
+	f.fire()
+}
+
+// WaitForTimer blocks until a timer is set to fire at deadline, so tests know the goroutine owning it waits for it
+// before moving the time.
+func (f *Fake) WaitForTimer(deadline time.Time) {
+	for {
+		f.mutex.Lock()
+		set := slices.ContainsFunc(f.timers, func(timer *fakeTimer) bool { return timer.deadline.Equal(deadline) })
+		reset := f.reset
+		f.mutex.Unlock()
+		if set {
+			return
+		}
+		<-reset
+	}
+}
+
+// fire sends the time on every timer that is due and forgets about them, callers must hold the mutex.
+func (f *Fake) fire() {
+	f.timers = slices.DeleteFunc(f.timers, func(timer *fakeTimer) bool {
+		if f.now.Before(timer.deadline) {
+			return false
+		}
+		timer.active = false
+		select {
+		case timer.c <- f.now:
+		default:
+		}
+		return true
+	})
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	active   bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Reset behaves like time.Timer.Reset, a stale time is drained from the channel.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+
+	wasActive := t.stop()
+	t.deadline = t.clock.now.Add(d)
+	t.active = true
+	t.clock.timers = append(t.clock.timers, t)
+	t.clock.fire()
+	close(t.clock.reset)
+	t.clock.reset = make(chan struct{})
+	return wasActive
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	return t.stop()
+}
+
+// stop deactivates the timer and drains its channel, callers must hold the mutex of the clock.
+func (t *fakeTimer) stop() bool {
+	wasActive := t.active
+	t.active = false
+	t.clock.timers = slices.DeleteFunc(t.clock.timers, func(timer *fakeTimer) bool { return timer == t })
+	select {
+	case <-t.c:
+	default:
+	}
+	return wasActive
+}
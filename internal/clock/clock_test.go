@@ -0,0 +1,77 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeTimer(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+	timer := fake.NewTimer(time.Minute)
+	after := fake.After(2 * time.Minute)
+
+	fake.Advance(59 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("expected the timer not to fire before its deadline")
+	default:
+	}
+
+	fake.Advance(time.Second)
+	select {
+	case fired := <-timer.C():
+		if !fired.Equal(start.Add(time.Minute)) {
+			t.Errorf("expected the timer to fire at its deadline, got %v", fired)
+		}
+	default:
+		t.Fatal("expected the timer to fire at its deadline")
+	}
+
+	if timer.Reset(time.Minute) {
+		t.Error("expected a fired timer to be inactive")
+	}
+	if !timer.Stop() {
+		t.Error("expected a reset timer to be active")
+	}
+	fake.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Error("expected a stopped timer not to fire")
+	case <-after:
+	default:
+		t.Error("expected After to fire")
+	}
+
+	// Resetting to a deadline that already passed fires right away and drops the stale time.
+	timer.Reset(0)
+	select {
+	case fired := <-timer.C():
+		if !fired.Equal(fake.Now()) {
+			t.Errorf("expected the current time, got %v", fired)
+		}
+	default:
+		t.Error("expected a timer without a delay to fire right away")
+	}
+}
+
+func TestFakeWaitForTimer(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fake.WaitForTimer(start.Add(time.Minute))
+	}()
+
+	fake.NewTimer(time.Hour)
+	select {
+	case <-done:
+		t.Fatal("expected WaitForTimer to wait for a timer with the given deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	fake.NewTimer(time.Minute)
+	<-done
+}
@@ -0,0 +1,196 @@
+// Package cron parses standard five field cron expressions (minute, hour, day of month, month, day of week)
+// and computes the times they match.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidExpression = errors.New("invalid cron expression")
+)
+
+type Schedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// Mirrors cron(8), when both day fields are restricted a day matches if either of them does.
+	dayOfMonthStar bool
+	dayOfWeekStar  bool
+}
+
+type fieldBounds struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteBounds     = fieldBounds{name: "minute", min: 0, max: 59}
+	hourBounds       = fieldBounds{name: "hour", min: 0, max: 23}
+	dayOfMonthBounds = fieldBounds{name: "day of month", min: 1, max: 31}
+	monthBounds      = fieldBounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as an alias for sunday and folded into 0 after parsing.
+	dayOfWeekBounds = fieldBounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d: %q", ErrInvalidExpression, len(fields), expr)
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if s.dayOfMonth, err = parseField(fields[2], dayOfMonthBounds); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if s.dayOfWeek, err = parseField(fields[4], dayOfWeekBounds); err != nil {
+		return nil, err
+	}
+	if s.dayOfWeek&(1<<7) != 0 {
+		s.dayOfWeek = s.dayOfWeek&^(1<<7) | 1
+	}
+
+	s.dayOfMonthStar = strings.HasPrefix(fields[2], "*")
+	s.dayOfWeekStar = strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+func parseField(field string, bounds fieldBounds) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: invalid step in %s field: %q", ErrInvalidExpression, bounds.name, part)
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*":
+			low, high = bounds.min, bounds.max
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart, bounds); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highPart, bounds); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if low, err = parseValue(rangePart, bounds); err != nil {
+				return 0, err
+			}
+			high = low
+			if hasStep {
+				high = bounds.max
+			}
+		}
+
+		if low > high {
+			return 0, fmt.Errorf("%w: inverted range in %s field: %q", ErrInvalidExpression, bounds.name, part)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func parseValue(value string, bounds fieldBounds) (int, error) {
+	if v, ok := bounds.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid value in %s field: %q", ErrInvalidExpression, bounds.name, value)
+	}
+	if v < bounds.min || v > bounds.max {
+		return 0, fmt.Errorf("%w: %s value out of range (min: %d, max: %d): %d", ErrInvalidExpression, bounds.name, bounds.min, bounds.max, v)
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after t that matches the schedule, in the location of t.
+// A zero time is returned if nothing matches within the next five years, e.g. for "0 0 30 2 *".
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+	for t.Year() <= yearLimit {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// ActiveWindow reports whether t falls inside a window of the given duration that opens at a match of the schedule,
+// along with the start of that window.
+func (s *Schedule) ActiveWindow(t time.Time, duration time.Duration) (time.Time, bool) {
+	start := s.Next(t.Add(-duration))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := has(s.dayOfMonth, t.Day())
+	dow := has(s.dayOfWeek, int(t.Weekday()))
+	if s.dayOfMonthStar || s.dayOfWeekStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<v) != 0
+}
@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+	} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("expected ErrInvalidExpression for %q, got %v", expr, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, time.March, 14, 10, 7, 30, 0, time.UTC)
+	for _, test := range []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.March, 14, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.March, 14, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, time.March, 15, 3, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2026, time.March, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		schedule, err := Parse(test.expr)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", test.expr, err)
+		}
+
+		if got := schedule.Next(base); !got.Equal(test.expected) {
+			t.Errorf("Next(%q) = %v, expected %v", test.expr, got, test.expected)
+		}
+	}
+}
+
+func TestActiveWindow(t *testing.T) {
+	schedule, err := Parse("0 3 * * *")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	windowStart := time.Date(2026, time.March, 14, 3, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		at     time.Time
+		active bool
+	}{
+		{windowStart.Add(-time.Second), false},
+		{windowStart, true},
+		{windowStart.Add(29 * time.Minute), true},
+		{windowStart.Add(30 * time.Minute), false},
+	} {
+		start, active := schedule.ActiveWindow(test.at, 30*time.Minute)
+		if active != test.active {
+			t.Errorf("ActiveWindow(%v) = %t, expected %t", test.at, active, test.active)
+		}
+		if active && !start.Equal(windowStart) {
+			t.Errorf("ActiveWindow(%v) start = %v, expected %v", test.at, start, windowStart)
+		}
+	}
+}
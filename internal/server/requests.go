@@ -1,5 +1,27 @@
 package server
 
+import "time"
+
 type pulseRequestBody struct {
-	ServiceName string `json:"service_name"`
+	ServiceName string             `json:"service_name"`
+	Metrics     map[string]float64 `json:"metrics"`
+}
+
+type ackRequestBody struct {
+	Comment   string `json:"comment"`
+	ExpiresIn string `json:"expires_in"`
+}
+
+type pauseRequestBody struct {
+	Comment    string `json:"comment"`
+	AutoResume bool   `json:"auto_resume"`
+}
+
+type silenceRequestBody struct {
+	Services []string  `json:"services"`
+	Tags     []string  `json:"tags"`
+	Comment  string    `json:"comment"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Duration string    `json:"duration"`
 }
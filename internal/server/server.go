@@ -2,34 +2,60 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
 
+	"service-uptime-center/internal/app/apperror"
 	service "service-uptime-center/internal/service"
 	mw "service-uptime-center/middleware"
 	"service-uptime-center/notification"
 )
 
-func ServeAndAwaitTermination(port uint16) {
+// ServeAndAwaitTermination serves the endpoints until ctx is done, then gives the requests in flight up to
+// shutdownTimeout to finish. It returns early if the server fails, e.g. because the port is taken.
+func ServeAndAwaitTermination(ctx context.Context, port uint16, shutdownTimeout time.Duration) error {
 	server := http.Server{Addr: fmt.Sprintf(":%d", port)}
+	failed := make(chan error, 1)
 	go func() {
 		slog.Info("Starting HTTP server", "port", port)
-		server.ListenAndServe()
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
 	}()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case err := <-failed:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down HTTP server", "timeout", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("http server did not shut down in time: %w", err)
+	}
+	return nil
+}
 
-	server.Close()
+// ConfigReloader reloads the config at runtime, app.Reloader implements it.
+type ConfigReloader interface {
+	// AllNotifiers returns the notifiers of the config currently in use, these have been validated.
+	AllNotifiers() []string
+	Reload() error
 }
 
-func SetupEndpoints(authToken string, serviceManager *service.Manager, notificationManager *notification.Manager, notifiers []string) {
+func SetupEndpoints(authToken string, serviceManager *service.Manager, notificationManager *notification.Manager, reloader ConfigReloader) {
 	if serviceManager == nil {
 		panic("manager cannot be passed as nil")
 	}
@@ -51,7 +77,7 @@ func SetupEndpoints(authToken string, serviceManager *service.Manager, notificat
 				mw.MiddlewareMethodGet,
 			},
 			func(w http.ResponseWriter, r *http.Request) {
-				results := notificationManager.TestAuth(notifiers)
+				results := notificationManager.TestAuth(reloader.AllNotifiers())
 				healthy := true
 				authResults := make(map[string]string, len(results))
 				for _, r := range results {
@@ -84,7 +110,11 @@ func SetupEndpoints(authToken string, serviceManager *service.Manager, notificat
 				mw.MiddlewareMethodGet,
 			},
 			func(w http.ResponseWriter, r *http.Request) {
-				json, err := serviceManager.GetStatusJSON()
+				query := r.URL.Query()
+				json, err := serviceManager.GetStatusJSON(service.StatusFilter{
+					Tag:   query.Get("tag"),
+					Group: query.Get("group"),
+				})
 				if err != nil {
 					http.Error(w, "failed to serialize services", http.StatusInternalServerError)
 					return
@@ -94,6 +124,30 @@ func SetupEndpoints(authToken string, serviceManager *service.Manager, notificat
 				fmt.Fprint(w, string(json))
 			},
 		},
+		{
+			"/admin/reload",
+			[]mw.Middleware{
+				mw.MiddlewareMethodPost,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				if err := reloader.Reload(); err != nil {
+					slog.Error("Failed to reload config, keeping the previous one", "error", err)
+					http.Error(w, fmt.Sprintf("failed to reload config, keeping the previous one: %v", err), http.StatusUnprocessableEntity)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+			},
+		},
+		{
+			"/reports/uptime",
+			[]mw.Middleware{
+				mw.MiddlewareMethodGet,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, serviceManager.UptimeReport())
+			},
+		},
 		{
 			"/pulse",
 			[]mw.Middleware{
@@ -110,7 +164,7 @@ func SetupEndpoints(authToken string, serviceManager *service.Manager, notificat
 					return
 				}
 
-				if !serviceManager.UpdatePulse(body.ServiceName) {
+				if !serviceManager.UpdatePulseWithMetrics(body.ServiceName, body.Metrics) {
 					slog.Warn("ServiceName doesn't exist in Mapper", "endpoint", "/pulse", "body", r.Body)
 					http.Error(w, "Invalid Service Name", http.StatusBadRequest)
 					return
@@ -121,6 +175,235 @@ func SetupEndpoints(authToken string, serviceManager *service.Manager, notificat
 				slog.Info("Pulse request successfully executed.", "service", body.ServiceName)
 			},
 		},
+		{
+			"/services",
+			[]mw.Middleware{
+				mw.MiddlewareMethodPost,
+				mw.MiddlewareContentTypeJSON,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				var body service.ServiceDefinition
+				if !decodeJSONBody(w, r, &body) {
+					return
+				}
+				if !validateNotifiers(w, reloader.AllNotifiers(), body.Notifiers, body.WarningNotifiers) {
+					return
+				}
+
+				created, err := serviceManager.CreateService(body)
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				writeJSON(w, http.StatusCreated, &created)
+			},
+		},
+		{
+			"/services/{name}",
+			[]mw.Middleware{
+				mw.CreateMethodsMiddleware(http.MethodPut, http.MethodDelete),
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				name := r.PathValue("name")
+				if r.Method == http.MethodDelete {
+					if err := serviceManager.DeleteService(name); err != nil {
+						writeServiceError(w, err)
+						return
+					}
+
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+
+				if !mw.MiddlewareContentTypeJSON(w, r) {
+					return
+				}
+
+				var body service.ServiceDefinition
+				if !decodeJSONBody(w, r, &body) {
+					return
+				}
+				if !validateNotifiers(w, reloader.AllNotifiers(), body.Notifiers, body.WarningNotifiers) {
+					return
+				}
+
+				updated, err := serviceManager.UpdateService(name, body)
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, &updated)
+			},
+		},
+		{
+			"/services/{name}/events",
+			[]mw.Middleware{
+				mw.MiddlewareMethodGet,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				query, err := parseEventQuery(r.URL.Query())
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				events, total, err := serviceManager.Events(r.PathValue("name"), query)
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, map[string]any{
+					"events": events,
+					"total":  total,
+				})
+			},
+		},
+		{
+			"/services/{name}/ack",
+			[]mw.Middleware{
+				mw.MiddlewareMethodPost,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				var body ackRequestBody
+				if !decodeOptionalJSONBody(w, r, &body) {
+					return
+				}
+
+				var expiresIn time.Duration
+				if len(body.ExpiresIn) != 0 {
+					var err error
+					if expiresIn, err = time.ParseDuration(body.ExpiresIn); err != nil {
+						http.Error(w, "Invalid expires_in duration", http.StatusBadRequest)
+						return
+					}
+				}
+
+				name := r.PathValue("name")
+				ack, err := serviceManager.Acknowledge(name, body.Comment, expiresIn)
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, ack)
+				slog.Info("Acknowledge request successfully executed.", "service", name)
+			},
+		},
+		{
+			"/services/{name}/pause",
+			[]mw.Middleware{
+				mw.MiddlewareMethodPost,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				var body pauseRequestBody
+				if !decodeOptionalJSONBody(w, r, &body) {
+					return
+				}
+
+				name := r.PathValue("name")
+				pause, err := serviceManager.Pause(name, body.Comment, body.AutoResume)
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, pause)
+			},
+		},
+		{
+			"/services/{name}/resume",
+			[]mw.Middleware{
+				mw.MiddlewareMethodPost,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				name := r.PathValue("name")
+				if err := serviceManager.Resume(name); err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintf(w, "Service '%s' resumed successfully", name)
+			},
+		},
+		{
+			"/silences",
+			[]mw.Middleware{
+				mw.CreateMethodsMiddleware(http.MethodGet, http.MethodPost),
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					writeJSON(w, http.StatusOK, map[string]any{
+						"silences": serviceManager.ListSilences(),
+					})
+					return
+				}
+
+				if !mw.MiddlewareContentTypeJSON(w, r) {
+					return
+				}
+
+				var body silenceRequestBody
+				if !decodeJSONBody(w, r, &body) {
+					return
+				}
+
+				var duration time.Duration
+				if len(body.Duration) != 0 {
+					var err error
+					if duration, err = time.ParseDuration(body.Duration); err != nil {
+						http.Error(w, "Invalid duration", http.StatusBadRequest)
+						return
+					}
+				}
+
+				silence, err := serviceManager.CreateSilence(service.Silence{
+					Services: body.Services,
+					Tags:     body.Tags,
+					Comment:  body.Comment,
+					StartsAt: body.StartsAt,
+					EndsAt:   body.EndsAt,
+				}, duration)
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				writeJSON(w, http.StatusCreated, silence)
+			},
+		},
+		{
+			"/silences/{id}",
+			[]mw.Middleware{
+				mw.MiddlewareMethodDelete,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				if err := serviceManager.DeleteSilence(r.PathValue("id")); err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			"/silences/{id}/expire",
+			[]mw.Middleware{
+				mw.MiddlewareMethodPost,
+			},
+			func(w http.ResponseWriter, r *http.Request) {
+				silence, err := serviceManager.ExpireSilence(r.PathValue("id"))
+				if err != nil {
+					writeServiceError(w, err)
+					return
+				}
+
+				writeJSON(w, http.StatusOK, silence)
+			},
+		},
 	}
 
 	for _, endpoint := range endpoints {
@@ -137,3 +420,119 @@ func SetupEndpoints(authToken string, serviceManager *service.Manager, notificat
 		})
 	}
 }
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		slog.Warn("Failed to decode json from request body", "path", r.URL.Path, "error", err)
+		http.Error(w, "Invalid JSON in Request", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// decodeOptionalJSONBody decodes the request body into dst, an empty body is accepted and leaves dst untouched.
+func decodeOptionalJSONBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+		slog.Warn("Failed to decode json from request body", "path", r.URL.Path, "error", err)
+		http.Error(w, "Invalid JSON in Request", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func parseEventQuery(values url.Values) (service.EventQuery, error) {
+	var query service.EventQuery
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{
+		{"limit", &query.Limit},
+		{"offset", &query.Offset},
+	} {
+		if raw := values.Get(param.name); len(raw) != 0 {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value < 0 {
+				return query, fmt.Errorf("invalid %s: %q", param.name, raw)
+			}
+			*param.dst = value
+		}
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"since", &query.Since},
+		{"until", &query.Until},
+	} {
+		if raw := values.Get(param.name); len(raw) != 0 {
+			value, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s, expected RFC 3339: %q", param.name, raw)
+			}
+			*param.dst = value
+		}
+	}
+
+	return query, nil
+}
+
+// validateNotifiers only allows notifiers that have been validated, others might be missing their settings. Like in
+// the config, a notifier may be in several of the lists but only once per list.
+func validateNotifiers(w http.ResponseWriter, available []string, lists ...[]string) bool {
+	for _, requested := range lists {
+		for i, protocol := range requested {
+			if !slices.Contains(available, protocol) {
+				writeServiceError(w, fmt.Errorf("%w: %s is not configured", apperror.ErrInvalidNotifProtocol, protocol))
+				return false
+			}
+			if slices.Contains(requested[:i], protocol) {
+				writeServiceError(w, fmt.Errorf("%w: %s", notification.ErrDuplicateNotifyProtocol, protocol))
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("Failed to encode json response", "error", err)
+	}
+}
+
+func writeServiceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, apperror.ErrServiceNotFound), errors.Is(err, apperror.ErrSilenceNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, apperror.ErrServiceNotProblematic),
+		errors.Is(err, apperror.ErrServiceNotPaused),
+		errors.Is(err, apperror.ErrDuplicateServiceNames),
+		errors.Is(err, apperror.ErrStaticService),
+		errors.Is(err, apperror.ErrServiceHasDependents):
+		status = http.StatusConflict
+	case errors.Is(err, apperror.ErrInvalidAckExpiry),
+		errors.Is(err, apperror.ErrInvalidSilence),
+		errors.Is(err, apperror.ErrInvalidServiceDefinition),
+		errors.Is(err, apperror.ErrInvalidNotifProtocol),
+		errors.Is(err, notification.ErrDuplicateNotifyProtocol),
+		errors.Is(err, apperror.ErrUnknownDependency),
+		errors.Is(err, apperror.ErrDependencyCycle),
+		errors.Is(err, apperror.ErrInvalidServiceName),
+		errors.Is(err, apperror.ErrInvalidWarningThreshold),
+		errors.Is(err, apperror.ErrInvalidPulsePolicy),
+		errors.Is(err, apperror.ErrInvalidMetricRule),
+		errors.Is(err, apperror.ErrHeartbeatTimeoutTooShort):
+		status = http.StatusBadRequest
+	}
+
+	slog.Warn("Service request failed", "status", status, "error", err)
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/notification"
+)
+
+// checkState is what the last run of an active check of a service left behind.
+type checkState struct {
+	// failure holds why the check failed, it's cleared once the check passes again.
+	failure      string
+	failureSince time.Time
+	// warning is reported by the passing check, warningNotified is the last warning of the check that has been sent.
+	warning         string
+	warningNotified string
+	// status is the status text of the passing check, e.g. the output of a command check.
+	status string
+}
+
+// check returns the state of the check with the given ID, creating it if needed.
+func (s *Service) check(id string) *checkState {
+	if s.checks == nil {
+		s.checks = make(map[string]*checkState)
+	}
+	state, ok := s.checks[id]
+	if !ok {
+		state = &checkState{}
+		s.checks[id] = state
+	}
+	return state
+}
+
+// checkIDs returns the IDs of the checks that reported a result, sorted.
+func (s *Service) checkIDs() []string {
+	return slices.Sorted(maps.Keys(s.checks))
+}
+
+// checkFailureSince returns since when the earliest of the failing checks fails, the zero time if none fails.
+func (s *Service) checkFailureSince() time.Time {
+	var since time.Time
+	for _, state := range s.checks {
+		if !state.failureSince.IsZero() && (since.IsZero() || state.failureSince.Before(since)) {
+			since = state.failureSince
+		}
+	}
+	return since
+}
+
+// checkMessages returns the non-empty failures, warnings or status texts of the checks by check ID.
+func (s *Service) checkMessages(message func(state *checkState) string) map[string]string {
+	messages := make(map[string]string)
+	for id, state := range s.checks {
+		if len(message(state)) != 0 {
+			messages[id] = message(state)
+		}
+	}
+	return messages
+}
+
+// hasUnsentCheckWarning reports whether any check raised a warning that hasn't been sent yet.
+func (s *Service) hasUnsentCheckWarning() bool {
+	for _, state := range s.checks {
+		if len(state.warning) != 0 && state.warning != state.warningNotified {
+			return true
+		}
+	}
+	return false
+}
+
+// clearCheckFailures forgets every check failure, the checks have to fail again to count.
+func (s *Service) clearCheckFailures() {
+	for _, state := range s.checks {
+		state.failure = ""
+		state.failureSince = time.Time{}
+	}
+}
+
+// retainChecks drops the state of every check whose ID isn't in ids, e.g. because a reload removed it.
+func (s *Service) retainChecks(ids []string) {
+	maps.DeleteFunc(s.checks, func(id string, _ *checkState) bool { return !slices.Contains(ids, id) })
+}
+
+// ReportCheckResult feeds the result of the active check with the given ID into the service. A successful check
+// counts as a pulse, its message is the status text of the check or, if warning is set, a warning, e.g. about an
+// expiring certificate, that is sent to the warning notifiers once. A failed check opens an incident right away and
+// the service stays problematic while any of its checks fails. Metrics measured by the check are recorded like the
+// metrics of a pulse.
+func (m *Manager) ReportCheckResult(name string, check string, ok bool, warning bool, message string, metrics map[string]float64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	service, exists := m.lookup[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", apperror.ErrServiceNotFound, name)
+	}
+
+	// Paused services aren't monitored, only a successful check still resumes one paused with auto resume.
+	if service.Pause != nil && !(ok && service.Pause.AutoResume) {
+		slog.Info("Ignoring check result of paused service", "service", name, "check", check, "ok", ok, "message", message)
+		return nil
+	}
+
+	now := m.clock.Now()
+	state := service.check(check)
+	if ok {
+		state.failure = ""
+		state.failureSince = time.Time{}
+		state.status = ""
+		if !warning {
+			state.status = message
+			message = ""
+		}
+		if len(message) != 0 && state.warning != message {
+			m.recordEvent(service, EventCheckWarning, now, fmt.Sprintf("%s: %s", check, message))
+		}
+		state.warning = message
+		if len(message) == 0 {
+			state.warningNotified = ""
+		}
+		m.pulse(service, metrics)
+		return nil
+	}
+
+	state.status = ""
+
+	if state.failure != message {
+		m.recordEvent(service, EventCheckFailed, now, fmt.Sprintf("%s: %s", check, message))
+	}
+	if state.failureSince.IsZero() {
+		state.failureSince = now
+	}
+	state.failure = message
+	if len(metrics) != 0 {
+		service.LastMetrics = metrics
+	}
+	m.openIncident(service, service.downSince())
+	m.wakeUp()
+	return nil
+}
+
+// RetainChecks drops the state of the checks that are no longer configured, checks maps every service to the IDs of
+// its checks. Services without checks lose all of their check state, an incident kept open by a dropped check ends.
+func (m *Manager) RetainChecks(checks map[string][]string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.clock.Now()
+	for _, service := range m.services {
+		service.retainChecks(checks[service.Name])
+		if !service.isProblematic() {
+			m.closeIncident(service, now)
+		}
+	}
+	m.wakeUp()
+}
+
+// handleCheckWarnings sends every new check warning to the warning targets of its service, a warning is sent once
+// until its message changes.
+func (m *Manager) handleCheckWarnings(notificationManager *notification.Manager, warningNotifiers []string, targets notification.ProtocolTargets) {
+	m.mutex.Lock()
+
+	now := m.clock.Now()
+	reports := newReportsByTargets(newLineReport)
+	for _, service := range m.services {
+		for _, id := range service.checkIDs() {
+			state := service.checks[id]
+			if len(state.warning) == 0 || state.warning == state.warningNotified {
+				continue
+			}
+
+			state.warningNotified = state.warning
+			if reason, silenced := m.silencedBy(service, now); silenced {
+				slog.Info("Leaving out check warning from notification because the service is silenced.", "service", service.Name, "check", id, "silenced by", reason)
+				continue
+			}
+
+			reports.forTargets(service.warningTargets(m.cfg.Routes, warningNotifiers, targets)).
+				add(service, fmt.Sprintf("%s: %s", service.Name, state.warning))
+		}
+	}
+
+	m.mutex.Unlock()
+
+	for _, report := range reports.reports {
+		m.sendReport(notificationManager, report.targets, report.services, notification.SendData{
+			Title: fmt.Sprintf("Checks raised %d warnings", len(report.lines)),
+			Body:  report.body(""),
+		})
+	}
+}
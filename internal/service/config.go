@@ -4,11 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"service-uptime-center/internal/app/apperror"
-	"time"
 )
 
 type Config struct {
-	Services []Service `yaml:"services"`
+	Services           []Service             `yaml:"services"`
+	MaintenanceWindows []MaintenanceWindow   `yaml:"maintenance_windows"`
+	AutoProvision      []ProvisionTemplate   `yaml:"auto_provision"`
+	Routes             []Route               `yaml:"routes"`
+	Flapping           FlappingSettings      `yaml:"flapping"`
+	Events             EventSettings         `yaml:"events"`
+	Uptime             UptimeSettings        `yaml:"uptime"`
+	SummaryReport      SummaryReportSettings `yaml:"summary_report"`
+	// StateDirectory is where runtime state such as silences is persisted, it's kept in memory only if left empty.
+	StateDirectory string `yaml:"state_directory"`
 }
 
 func (c *Config) MarshalJSON() ([]byte, error) {
@@ -22,18 +30,59 @@ func (c *Config) Validate() error {
 		return apperror.ErrNoServices
 	}
 
+	for i := range c.Services {
+		if err := c.Services[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	services := make([]*Service, 0, len(c.Services))
+	for i := range c.Services {
+		services = append(services, &c.Services[i])
+	}
+	if err := validateDependencies(services); err != nil {
+		return err
+	}
+
+	names := make(map[string]struct{}, len(c.Services))
 	for _, service := range c.Services {
-		const MinHeartbeatFreq = time.Second * 60
-		if service.HeartbeatTimeoutDuration < MinHeartbeatFreq {
-			return fmt.Errorf("%w (min: %v): %v", apperror.ErrHeartbeatTimeoutTooShort, MinHeartbeatFreq, service.HeartbeatTimeoutDuration)
+		names[service.Name] = struct{}{}
+	}
+	if err := c.Flapping.validate(); err != nil {
+		return err
+	}
+	if err := c.Events.validate(c.StateDirectory); err != nil {
+		return err
+	}
+	if err := c.SummaryReport.validate(); err != nil {
+		return err
+	}
+
+	for _, route := range c.Routes {
+		if len(route.Tags) == 0 || len(route.Notifiers) == 0 {
+			return fmt.Errorf("%w: both tags and notifiers are required: %+v", apperror.ErrInvalidRoute, route)
+		}
+	}
+
+	for i := range c.AutoProvision {
+		if err := c.AutoProvision[i].validate(); err != nil {
+			return err
 		}
+	}
 
-		const MinNameLen = 2
-		const MaxNameLen = 64
-		if len(service.Name) < MinNameLen || len(service.Name) > MaxNameLen {
-			return fmt.Errorf("%w (min: %d, max: %d): %s", apperror.ErrInvalidServiceName, MinNameLen, MaxNameLen, service.Name)
+	for i := range c.MaintenanceWindows {
+		if err := c.MaintenanceWindows[i].validate(names); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+// Route sends problems with services carrying any of Tags to Notifiers instead of the global notifiers, and
+// warnings about them being late to WarningNotifiers if set.
+type Route struct {
+	Tags             []string `yaml:"tags"`
+	Notifiers        []string `yaml:"notifiers"`
+	WarningNotifiers []string `yaml:"warning_notifiers"`
+}
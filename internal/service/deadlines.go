@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"service-uptime-center/notification"
+)
+
+// evaluationDue reports whether a problematic service has to be handled: as soon as it goes down, then again
+// every pollFrequency while it stays down, and whenever its reports were suppressed, so it's reported once the
+// suppression ends.
+func (s *Service) evaluationDue(now time.Time, pollFrequency time.Duration) bool {
+	return s.reportSuppressed || s.LastProblem.Before(s.downSince()) || !now.Before(s.LastProblem.Add(pollFrequency))
+}
+
+// nextDeadline returns when the service has to be looked at next, the zero time if it has no deadline. A problematic
+// service whose reports are suppressed, see Manager.suppressedUntil, is looked at again when the suppression ends.
+func (s *Service) nextDeadline(now time.Time, pollFrequency time.Duration, suppressedUntil func() (time.Time, bool)) time.Time {
+	switch {
+	case s.Pause != nil:
+		return time.Time{}
+	case s.isFlapping() && !s.flappingNotified,
+		s.hasUnsentCheckWarning():
+		return now
+	case s.isProblematic():
+		if until, suppressed := suppressedUntil(); suppressed {
+			return until
+		}
+		if s.LastProblem.Before(s.downSince()) {
+			return s.downSince()
+		}
+		return s.LastProblem.Add(pollFrequency)
+	}
+
+	deadline := s.downSince()
+	if late := s.LastPulse.Add(s.warningTimeout()); s.WarningThreshold > 0 && !s.lateNotified && late.Before(deadline) {
+		deadline = late
+	}
+	return deadline
+}
+
+// nextDeadline returns the earliest deadline of all services, but no later than pollFrequency from now. Services
+// whose reports are suppressed are marked, so they're evaluated once the suppression ends, even if that's before
+// their next regular evaluation.
+func (m *Manager) nextDeadline(pollFrequency time.Duration) time.Time {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.clock.Now()
+	next := now.Add(pollFrequency)
+	down := m.downServices()
+	for _, service := range m.services {
+		suppressedUntil := func() (time.Time, bool) {
+			until, suppressed := m.suppressedUntil(service, now, down)
+			service.reportSuppressed = service.reportSuppressed || suppressed
+			return until, suppressed
+		}
+		if deadline := service.nextDeadline(now, pollFrequency, suppressedUntil); !deadline.IsZero() && deadline.Before(next) {
+			next = deadline
+		}
+	}
+	return next
+}
+
+// getDueProblematicServices returns the problematic services whose evaluation is due.
+func (m *Manager) getDueProblematicServices(pollFrequency time.Duration) []*Service {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	now := m.clock.Now()
+	var due []*Service
+	for _, service := range m.services {
+		if service.isProblematic() && service.evaluationDue(now, pollFrequency) {
+			due = append(due, service)
+		}
+	}
+	return due
+}
+
+// wakeUp makes the monitor recompute its deadline, e.g. after a pulse moved the deadline of a service. It never
+// blocks, so it's safe to call while holding the mutex.
+func (m *Manager) wakeUp() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// monitor handles the services whenever one of them reaches its deadline, until ctx is done.
+func (m *Manager) monitor(ctx context.Context, notificationManager *notification.Manager) {
+	timer := m.clock.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.wake:
+		case <-timer.C():
+			m.evaluate(notificationManager, m.instructions())
+		}
+
+		timer.Reset(m.nextDeadline(m.instructions().Timings.IncidentsPollFreq).Sub(m.clock.Now()))
+	}
+}
+
+// evaluate handles every service that reached its deadline.
+func (m *Manager) evaluate(notificationManager *notification.Manager, instr MonitoringInstructions) {
+	m.handleFlappingServices(notificationManager, instr.Notifiers)
+	m.handleLateServices(notificationManager, instr.WarningNotifiers, instr.Notifiers)
+	m.handleCheckWarnings(notificationManager, instr.WarningNotifiers, instr.Notifiers)
+
+	problematic := m.getDueProblematicServices(instr.Timings.IncidentsPollFreq)
+	if len(problematic) > 0 {
+		m.handleProblematicServices(notificationManager, instr.Notifiers, problematic, instr.Timings.ProblematicReportCooldown)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"service-uptime-center/internal/app/apperror"
+)
+
+// validateDependencies makes sure every dependency refers to a known service and that there are no cycles.
+func validateDependencies(services []*Service) error {
+	lookup := make(map[string]*Service, len(services))
+	for _, service := range services {
+		lookup[service.Name] = service
+	}
+
+	for _, service := range services {
+		for _, dependency := range service.DependsOn {
+			if _, ok := lookup[dependency]; !ok {
+				return fmt.Errorf("%w: %s depends on %s", apperror.ErrUnknownDependency, service.Name, dependency)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(services))
+	var path []string
+	var visit func(service *Service) error
+	visit = func(service *Service) error {
+		switch state[service.Name] {
+		case visiting:
+			start := slices.Index(path, service.Name)
+			return fmt.Errorf("%w: %v", apperror.ErrDependencyCycle, append(path[start:], service.Name))
+		case visited:
+			return nil
+		}
+
+		state[service.Name] = visiting
+		path = append(path, service.Name)
+		for _, dependency := range service.DependsOn {
+			if err := visit(lookup[dependency]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[service.Name] = visited
+		return nil
+	}
+
+	for _, service := range services {
+		if err := visit(service); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rootCauses walks the dependencies of a service through the ones that are down and returns the names of those
+// that are down without any of their own dependencies being down. Callers must hold the mutex.
+func (m *Manager) rootCauses(service *Service, down map[string]bool) []string {
+	var causes []string
+	seen := make(map[string]bool)
+
+	var walk func(service *Service)
+	walk = func(service *Service) {
+		for _, name := range service.DependsOn {
+			if !down[name] || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			dependency := m.lookup[name]
+			if !slices.ContainsFunc(dependency.DependsOn, func(n string) bool { return down[n] }) {
+				causes = append(causes, name)
+			}
+			walk(dependency)
+		}
+	}
+	walk(service)
+
+	slices.Sort(causes)
+	return causes
+}
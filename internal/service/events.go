@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+)
+
+type EventType string
+
+const (
+	EventPulse            EventType = "pulse"
+	EventLate             EventType = "late"
+	EventMetricViolation  EventType = "metric_violation"
+	EventCheckFailed      EventType = "check_failed"
+	EventCheckWarning     EventType = "check_warning"
+	EventIncidentOpened   EventType = "incident_opened"
+	EventIncidentResolved EventType = "incident_resolved"
+	EventNotification     EventType = "notification"
+	EventAcknowledged     EventType = "acknowledged"
+	EventPaused           EventType = "paused"
+	EventResumed          EventType = "resumed"
+)
+
+type Event struct {
+	Time    time.Time `json:"time"`
+	Service string    `json:"service"`
+	Type    EventType `json:"type"`
+	Message string    `json:"message,omitempty"`
+}
+
+// EventSettings bounds the event history kept per service and optionally appends every event to a log in the
+// state directory, which is replayed on startup so the history survives restarts.
+type EventSettings struct {
+	HistorySize int  `yaml:"history_size"`
+	Persist     bool `yaml:"persist"`
+}
+
+const DefaultEventHistorySize = 500
+
+func (e *EventSettings) validate(stateDirectory string) error {
+	if e.HistorySize < 0 {
+		return fmt.Errorf("%w: history_size can't be negative: %d", apperror.ErrInvalidEventSettings, e.HistorySize)
+	}
+	if e.Persist && len(stateDirectory) == 0 {
+		return fmt.Errorf("%w: persist requires state_directory to be set", apperror.ErrInvalidEventSettings)
+	}
+	return nil
+}
+
+func (e *EventSettings) historySize() int {
+	if e.HistorySize == 0 {
+		return DefaultEventHistorySize
+	}
+	return e.HistorySize
+}
+
+// eventHistory is a fixed capacity ring buffer, once full the oldest event is overwritten.
+type eventHistory struct {
+	events []Event
+	next   int
+}
+
+func newEventHistory(capacity int) *eventHistory {
+	return &eventHistory{events: make([]Event, 0, capacity)}
+}
+
+func (h *eventHistory) add(event Event) {
+	if len(h.events) < cap(h.events) {
+		h.events = append(h.events, event)
+		return
+	}
+
+	h.events[h.next] = event
+	h.next = (h.next + 1) % len(h.events)
+}
+
+// all returns the events from oldest to newest.
+func (h *eventHistory) all() []Event {
+	return slices.Concat(h.events[h.next:], h.events[:h.next])
+}
+
+// EventQuery selects a page of events, newest first. Zero Since and Until leave the range open.
+type EventQuery struct {
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Offset int
+}
+
+const (
+	DefaultEventQueryLimit = 100
+	MaxEventQueryLimit     = 1000
+)
+
+// Events returns a page of the event history of a service along with the total number of events matching the time range.
+func (m *Manager) Events(name string, query EventQuery) ([]Event, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	service, exists := m.lookup[name]
+	if !exists {
+		return nil, 0, fmt.Errorf("%w: %s", apperror.ErrServiceNotFound, name)
+	}
+	if service.history == nil {
+		return []Event{}, 0, nil
+	}
+
+	var matching []Event
+	for _, event := range slices.Backward(service.history.all()) {
+		if !query.Since.IsZero() && event.Time.Before(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && event.Time.After(query.Until) {
+			continue
+		}
+		matching = append(matching, event)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultEventQueryLimit
+	}
+	limit = min(limit, MaxEventQueryLimit)
+
+	start := min(max(query.Offset, 0), len(matching))
+	end := min(start+limit, len(matching))
+	return slices.Clone(matching[start:end]), len(matching), nil
+}
+
+// recordEvent adds an event to the history of the service and the persisted log, callers must hold the mutex.
+func (m *Manager) recordEvent(service *Service, eventType EventType, at time.Time, message string) {
+	event := Event{
+		Time:    at,
+		Service: service.Name,
+		Type:    eventType,
+		Message: message,
+	}
+
+	if service.history == nil {
+		service.history = newEventHistory(m.cfg.Events.historySize())
+	}
+	service.history.add(event)
+
+	if m.eventLog == nil {
+		return
+	}
+	if err := json.NewEncoder(m.eventLog).Encode(event); err != nil {
+		slog.Error("Failed to persist event, it will be missing after a restart", "service", service.Name, "type", eventType, "error", err)
+		return
+	}
+	m.eventLogSize++
+	if m.eventLogSize > 2*len(m.services)*m.cfg.Events.historySize() {
+		if err := m.compactEventLog(); err != nil {
+			slog.Error("Failed to compact event log", "path", m.eventLogPath(), "error", err)
+		}
+	}
+}
+
+func (m *Manager) eventLogPath() string {
+	return filepath.Join(m.cfg.StateDirectory, "events.log")
+}
+
+// openEventLog replays the persisted events into the service histories and compacts the log down to what the
+// histories retain before opening it for appending. Events of services that no longer exist are dropped.
+func (m *Manager) openEventLog() error {
+	if err := m.replayEventLog(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.cfg.StateDirectory, 0o750); err != nil {
+		return err
+	}
+	return m.compactEventLog()
+}
+
+// replayEventLog adds the persisted events to the histories of their services.
+func (m *Manager) replayEventLog() error {
+	file, err := os.Open(m.eventLogPath())
+	if err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var event Event
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				slog.Warn("Skipping corrupt line in event log", "path", m.eventLogPath(), "error", err)
+				continue
+			}
+
+			service, exists := m.lookup[event.Service]
+			if !exists {
+				continue
+			}
+			if service.history == nil {
+				service.history = newEventHistory(m.cfg.Events.historySize())
+			}
+			service.history.add(event)
+		}
+		file.Close()
+
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// compactEventLog rewrites the event log with only the events the histories retain and reopens it for appending,
+// so it stays bounded by the history size. Callers must hold the mutex.
+func (m *Manager) compactEventLog() error {
+	var retained []Event
+	for _, service := range m.services {
+		if service.history != nil {
+			retained = append(retained, service.history.all()...)
+		}
+	}
+	slices.SortStableFunc(retained, func(a, b Event) int { return a.Time.Compare(b.Time) })
+
+	compacted, err := os.CreateTemp(m.cfg.StateDirectory, "events.log.*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(compacted.Name())
+
+	writer := bufio.NewWriter(compacted)
+	encoder := json.NewEncoder(writer)
+	for _, event := range retained {
+		if err := encoder.Encode(event); err != nil {
+			compacted.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		compacted.Close()
+		return err
+	}
+	if err := compacted.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(compacted.Name(), m.eventLogPath()); err != nil {
+		return err
+	}
+
+	// The previous log has been replaced, events appended to it from here on would be lost.
+	if m.eventLog != nil {
+		m.eventLog.Close()
+	}
+	m.eventLog, err = os.OpenFile(m.eventLogPath(), os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	m.eventLogSize = len(retained)
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/notification"
+)
+
+// FlappingSettings marks a service as flapping once it has changed between healthy and problematic Threshold times
+// within Window. Flapping services get a single notification instead of one per incident until they stabilize.
+// A zero threshold disables flapping detection.
+type FlappingSettings struct {
+	Window    time.Duration `yaml:"window"`
+	Threshold int           `yaml:"threshold"`
+}
+
+func (f *FlappingSettings) validate() error {
+	if f.Threshold == 0 {
+		return nil
+	}
+
+	const MinThreshold = 2
+	if f.Threshold < MinThreshold {
+		return fmt.Errorf("%w: threshold must be at least %d: %d", apperror.ErrInvalidFlappingSettings, MinThreshold, f.Threshold)
+	}
+	if f.Window <= 0 {
+		return fmt.Errorf("%w: window must be positive: %v", apperror.ErrInvalidFlappingSettings, f.Window)
+	}
+
+	return nil
+}
+
+func (s *Service) recordTransition(at time.Time, settings FlappingSettings) {
+	if settings.Threshold == 0 {
+		return
+	}
+
+	s.transitions = append(s.transitions, at)
+	s.updateFlapping(s.now(), settings)
+}
+
+// updateFlapping drops transitions that left the window and re-evaluates whether the service is flapping. With
+// flapping detection disabled the service forgets its transitions and stops flapping.
+func (s *Service) updateFlapping(now time.Time, settings FlappingSettings) {
+	if settings.Threshold == 0 {
+		s.transitions = nil
+		s.FlappingSince = time.Time{}
+		s.flappingNotified = false
+		return
+	}
+
+	cutoff := now.Add(-settings.Window)
+	s.transitions = slices.DeleteFunc(s.transitions, func(t time.Time) bool { return !t.After(cutoff) })
+
+	flapping := len(s.transitions) >= settings.Threshold
+	switch {
+	case flapping && !s.isFlapping():
+		slog.Warn("Service is flapping", "service", s.Name, "transitions", len(s.transitions), "window", settings.Window)
+		s.FlappingSince = now
+		s.flappingNotified = false
+	case !flapping && s.isFlapping():
+		slog.Info("Service stopped flapping", "service", s.Name, "flapping since", s.FlappingSince)
+		s.FlappingSince = time.Time{}
+	}
+}
+
+// flappingUntil returns when the service stops flapping unless it changes between healthy and problematic again.
+func (s *Service) flappingUntil(settings FlappingSettings) time.Time {
+	if settings.Threshold == 0 || len(s.transitions) < settings.Threshold {
+		return time.Time{}
+	}
+	return s.transitions[len(s.transitions)-settings.Threshold].Add(settings.Window)
+}
+
+func (s *Service) isFlapping() bool {
+	return !s.FlappingSince.IsZero()
+}
+
+// handleFlappingServices re-evaluates flapping for every service and sends one notification for each service that
+// started flapping since the last call.
+func (m *Manager) handleFlappingServices(notificationManager *notification.Manager, targets notification.ProtocolTargets) {
+	m.mutex.Lock()
+	if m.cfg.Flapping.Threshold == 0 {
+		m.mutex.Unlock()
+		return
+	}
+
+	now := m.clock.Now()
+	reports := newReportsByTargets(newLineReport)
+	for _, service := range m.services {
+		service.updateFlapping(now, m.cfg.Flapping)
+		if !service.isFlapping() || service.flappingNotified {
+			continue
+		}
+
+		service.flappingNotified = true
+		if reason, silenced := m.silencedBy(service, now); silenced {
+			slog.Info("Leaving out flapping service from notification because it's silenced.", "service", service.Name, "silenced by", reason)
+			continue
+		}
+
+		reports.forTargets(service.notificationTargets(m.cfg.Routes, targets)).
+			add(service, fmt.Sprintf("%s, %d changes within %s", service.Name, len(service.transitions), m.cfg.Flapping.Window))
+	}
+
+	m.mutex.Unlock()
+
+	for _, report := range reports.reports {
+		m.sendReport(notificationManager, report.targets, report.services, notification.SendData{
+			Title: fmt.Sprintf("%d services are flapping", len(report.lines)),
+			Body:  report.body("Problem reports for these services are suppressed until they stabilize.\n\n"),
+		})
+	}
+}
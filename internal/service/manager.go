@@ -1,29 +1,64 @@
 package service
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
 	"service-uptime-center/internal/app/apperror"
 	"service-uptime-center/internal/app/timings"
+	"service-uptime-center/internal/clock"
+	"service-uptime-center/internal/store"
 	"service-uptime-center/notification"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
 type Manager struct {
-	cfg    *Config
-	lookup map[string]*Service
-	mutex  sync.RWMutex
+	cfg      *Config
+	clock    clock.Clock
+	eventLog *os.File
+	// eventLogSize is the number of events in the event log, it's compacted once they outgrow the histories.
+	eventLogSize int
+	// services holds the static services from cfg followed by the dynamic ones, in registration order.
+	services []*Service
+	lookup   map[string]*Service
+	silences []*Silence
+	// lastSummary is when the previous summary report was sent, or monitoring started.
+	lastSummary time.Time
+	// lastWeeklyUptime is when the weekly uptime was last added to a summary report, or monitoring started.
+	lastWeeklyUptime time.Time
+	// instr holds the instructions passed to StartMonitoring, replaced on reload.
+	instr MonitoringInstructions
+	// wake tells the monitor that a deadline may have moved.
+	wake chan struct{}
+	// summaryWake tells the summary reports that their schedule may have changed.
+	summaryWake chan struct{}
+	// running tracks the goroutines started by StartMonitoring.
+	running sync.WaitGroup
+	mutex   sync.RWMutex
 }
 
 func NewManager(cfg *Config) (*Manager, error) {
-	now := time.Now()
+	return NewManagerWithClock(cfg, clock.Real{})
+}
+
+// NewManagerWithClock creates a manager that takes the time from clk, e.g. a fake clock in tests.
+func NewManagerWithClock(cfg *Config, clk clock.Clock) (*Manager, error) {
+	now := clk.Now()
 	lookup := make(map[string]*Service, len(cfg.Services))
+	services := make([]*Service, 0, len(cfg.Services))
 
 	for i := range cfg.Services {
+		cfg.Services[i].clock = clk
 		cfg.Services[i].LastPulse = now
+		cfg.Services[i].MonitoredSince = now
+		cfg.Services[i].resetPulseHistory(now)
 
 		_, ok := lookup[cfg.Services[i].Name]
 		if ok {
@@ -31,18 +66,77 @@ func NewManager(cfg *Config) (*Manager, error) {
 		}
 
 		lookup[cfg.Services[i].Name] = &cfg.Services[i]
+		services = append(services, &cfg.Services[i])
+	}
+
+	manager := &Manager{
+		cfg:         cfg,
+		clock:       clk,
+		services:    services,
+		lookup:      lookup,
+		wake:        make(chan struct{}, 1),
+		summaryWake: make(chan struct{}, 1),
+	}
+
+	if len(cfg.StateDirectory) == 0 {
+		slog.Warn("Running without a state directory, silences, dynamic services and uptime statistics will not survive a restart")
+		return manager, nil
+	}
+
+	if err := store.Load(manager.silencesPath(), &manager.silences); err != nil {
+		return nil, fmt.Errorf("failed to load silences: %w", err)
+	}
+	for _, silence := range manager.silences {
+		silence.clock = manager.clock
+	}
+	if err := manager.loadDynamicServices(now); err != nil {
+		return nil, fmt.Errorf("failed to load dynamic services: %w", err)
+	}
+	if err := manager.loadUptime(); err != nil {
+		return nil, fmt.Errorf("failed to load uptime statistics: %w", err)
+	}
+	manager.saveUptime()
+	if cfg.Events.Persist {
+		if err := manager.openEventLog(); err != nil {
+			return nil, fmt.Errorf("failed to open event log: %w", err)
+		}
 	}
 
-	return &Manager{
-		cfg:    cfg,
-		lookup: lookup,
-	}, nil
+	return manager, nil
 }
 
-func (m *Manager) GetStatusJSON() ([]byte, error) {
+// StatusFilter narrows down the services included in the status, empty fields match every service.
+type StatusFilter struct {
+	Tag   string
+	Group string
+}
+
+func (f StatusFilter) matches(service *Service) bool {
+	if len(f.Tag) != 0 && !slices.Contains(service.Tags, f.Tag) {
+		return false
+	}
+	if len(f.Group) != 0 && service.Group != f.Group {
+		return false
+	}
+	return true
+}
+
+func (m *Manager) GetStatusJSON(filter StatusFilter) ([]byte, error) {
 	// We could serialize the JSON as soon as any service changes come through and cache it
 	// instead of evaluating it each call.
-	json, err := json.Marshal(m.cfg)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	services := make([]*Service, 0, len(m.services))
+	for _, service := range m.services {
+		if filter.matches(service) {
+			services = append(services, service)
+		}
+	}
+
+	json, err := json.Marshal(map[string]any{
+		"services": services,
+	})
 	if err != nil {
 		slog.Error("Error marshaling service config", "error", err)
 		return nil, err
@@ -52,110 +146,529 @@ func (m *Manager) GetStatusJSON() ([]byte, error) {
 }
 
 func (m *Manager) UpdatePulse(name string) bool {
+	return m.UpdatePulseWithMetrics(name, nil)
+}
+
+// UpdatePulseWithMetrics records a pulse along with the metrics it carries, a metric violating one of the rules of
+// the service opens an incident even though the pulse arrived in time.
+func (m *Manager) UpdatePulseWithMetrics(name string, metrics map[string]float64) bool {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if service, exists := m.lookup[name]; exists {
-		service.LastPulse = time.Now()
-		return true
+	service, exists := m.lookup[name]
+	if !exists {
+		if service, exists = m.provision(name); !exists {
+			return false
+		}
+	}
+
+	m.pulse(service, metrics)
+	return true
+}
+
+// pulse records a pulse of the service and resolves its incident if it recovered, callers must hold the mutex.
+func (m *Manager) pulse(service *Service, metrics map[string]float64) {
+	now := m.clock.Now()
+	if service.Pause != nil && service.Pause.AutoResume {
+		slog.Info("Paused service pulsed, resuming monitoring", "service", service.Name)
+		m.resume(service, now)
+	}
+	if service.isProblematic() {
+		m.openIncident(service, service.downSince())
+	}
+	service.LastPulse = now
+	service.lateNotified = false
+	service.recordPulse(now)
+
+	// Metrics of paused services are kept for display, but their rules aren't checked.
+	var violations []string
+	if service.Pause == nil {
+		violations = service.recordMetrics(now, metrics)
+	} else if len(metrics) != 0 {
+		service.LastMetrics = metrics
+	}
+	if !service.isProblematic() {
+		m.closeIncident(service, now)
+	}
+	m.recordEvent(service, EventPulse, now, "")
+	if len(violations) != 0 {
+		m.recordEvent(service, EventMetricViolation, now, strings.Join(violations, "; "))
+		m.openIncident(service, service.downSince())
+	}
+
+	if service.Acknowledgement != nil && !service.isProblematic() {
+		slog.Info("Service recovered, clearing acknowledgement", "service", service.Name)
+		service.Acknowledgement = nil
+	}
+	m.wakeUp()
+}
+
+// Acknowledge suppresses problematic reports for the ongoing incident of a service until it recovers or,
+// when expiresIn is non-zero, until the acknowledgement expires.
+func (m *Manager) Acknowledge(name string, comment string, expiresIn time.Duration) (*Acknowledgement, error) {
+	if expiresIn < 0 {
+		return nil, fmt.Errorf("%w: %v", apperror.ErrInvalidAckExpiry, expiresIn)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	service, exists := m.lookup[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", apperror.ErrServiceNotFound, name)
+	}
+	if !service.isProblematic() {
+		return nil, fmt.Errorf("%w: %s", apperror.ErrServiceNotProblematic, name)
+	}
+
+	now := m.clock.Now()
+	ack := &Acknowledgement{
+		Comment:   comment,
+		CreatedAt: now,
+	}
+	if expiresIn > 0 {
+		ack.ExpiresAt = now.Add(expiresIn)
+	}
+
+	service.Acknowledgement = ack
+	m.recordEvent(service, EventAcknowledged, now, comment)
+	slog.Info("Service acknowledged", "service", name, "comment", comment, "expires_at", ack.ExpiresAt)
+	return ack, nil
+}
+
+// Pause stops reporting problems for a service until Resume is called or, if autoResume is set, until it pulses again.
+func (m *Manager) Pause(name string, comment string, autoResume bool) (*Pause, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	service, exists := m.lookup[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", apperror.ErrServiceNotFound, name)
+	}
+
+	// The time the service is paused doesn't count as downtime, an ongoing incident ends here and so does its
+	// acknowledgement.
+	now := m.clock.Now()
+	m.closeIncident(service, now)
+	service.Acknowledgement = nil
+	service.Pause = &Pause{
+		Comment:    comment,
+		AutoResume: autoResume,
+		CreatedAt:  now,
+	}
+	m.recordEvent(service, EventPaused, service.Pause.CreatedAt, comment)
+	slog.Info("Service paused", "service", name, "comment", comment, "auto_resume", autoResume)
+	return service.Pause, nil
+}
+
+// Resume restarts monitoring of a paused service, the heartbeat timeout starts over from the time of resuming.
+func (m *Manager) Resume(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	service, exists := m.lookup[name]
+	if !exists {
+		return fmt.Errorf("%w: %s", apperror.ErrServiceNotFound, name)
+	}
+	if service.Pause == nil {
+		return fmt.Errorf("%w: %s", apperror.ErrServiceNotPaused, name)
+	}
+
+	m.resume(service, m.clock.Now())
+	slog.Info("Service resumed", "service", name)
+	return nil
+}
+
+// resume restarts monitoring of a paused service as if it just pulsed, problems found before or while it was paused
+// are forgotten. Callers must hold the mutex.
+func (m *Manager) resume(service *Service, now time.Time) {
+	service.Pause = nil
+	service.LastPulse = now
+	service.resetPulseHistory(now)
+	service.metricViolations = nil
+	service.metricViolationSince = time.Time{}
+	service.clearCheckFailures()
+	m.closeIncident(service, now)
+	service.Acknowledgement = nil
+	m.recordEvent(service, EventResumed, now, "")
+	m.wakeUp()
+}
+
+// openIncident opens an incident for the service and records it, callers must hold the mutex.
+func (m *Manager) openIncident(service *Service, at time.Time) {
+	if service.openIncident(at, m.cfg.Flapping) {
+		m.recordEvent(service, EventIncidentOpened, at, "")
+	}
+}
+
+// closeIncident resolves the ongoing incident of the service and records it, callers must hold the mutex.
+func (m *Manager) closeIncident(service *Service, at time.Time) {
+	incidentStart := service.IncidentStart
+	if service.closeIncident(at, m.cfg.Flapping) {
+		m.recordEvent(service, EventIncidentResolved, at, fmt.Sprintf("down for %s", at.Sub(incidentStart).Round(time.Second)))
+		m.saveUptime()
 	}
-	return false
 }
 
 type MonitoringInstructions struct {
 	Timings   *timings.Timings
 	Notifiers notification.ProtocolTargets
+	// WarningNotifiers receive warnings about late services, problems are sent to Notifiers if left empty.
+	WarningNotifiers []string
 }
 
-func (m *Manager) StartMonitoring(notificationManager *notification.Manager, instr MonitoringInstructions) {
-	go func() {
+// StartMonitoring handles every service as soon as it reaches its deadline, repeats reports about services that
+// stay down every poll frequency and sends the summary reports, until ctx is done. Shutdown waits for it to stop.
+func (m *Manager) StartMonitoring(ctx context.Context, notificationManager *notification.Manager, instr MonitoringInstructions) {
+	start := m.clock.Now()
+	m.mutex.Lock()
+	m.lastSummary = start
+	m.lastWeeklyUptime = start
+	m.instr = instr
+	m.mutex.Unlock()
+
+	m.running.Go(func() { m.monitor(ctx, notificationManager) })
+	m.running.Go(func() {
+		timer := m.clock.NewTimer(m.nextSummary().Sub(m.clock.Now()))
+		defer timer.Stop()
 		for {
-			problematic := m.getProblematicServices()
-			if len(problematic) > 0 {
-				m.handleProblematicServices(notificationManager, instr.Notifiers, problematic, instr.Timings.ProblematicReportCooldown)
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.summaryWake:
+				// A reload may have changed the schedule, the report is due by the new one.
+				timer.Reset(m.nextSummary().Sub(m.clock.Now()))
+				continue
+			case <-timer.C():
 			}
 
-			time.Sleep(instr.Timings.IncidentsPollFreq)
-		}
-	}()
-
-	start := time.Now()
-	go func() {
-		for {
-			time.Sleep(instr.Timings.SuccessfulReportCooldown)
+			now := m.clock.Now()
+			report := m.summaryReport(now)
+			if m.weeklyUptimeDue(now) {
+				report.Body += "\n" + m.uptimeSummary(7*24*time.Hour)
+			}
 
-			if err := notificationManager.SendWithFallback(instr.Notifiers, notification.SendData{
-				Title: "Service Uptime Center running without any issues.",
-				Body:  "",
-			}); err != nil {
+			timer.Reset(m.nextSummary().Sub(m.clock.Now()))
+			if err := notificationManager.SendWithFallback(m.instructions().Notifiers, report); err != nil {
 				slog.Error("Cannot send notification, monitoring may be compromised", "error", err)
 				continue
 			}
 
-			slog.Info("Service is still running", "uptime", time.Since(start).String())
+			slog.Info("Service is still running", "uptime", m.clock.Now().Sub(start).String())
 		}
-	}()
+	})
 }
 
-func (m *Manager) getProblematicServices() []*Service {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+// Shutdown waits for the monitoring to stop after the context passed to StartMonitoring is done, so notifications
+// that are being sent are delivered, then saves the runtime state and closes the event log. It gives up waiting
+// when ctx is done, the state is saved either way.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(stopped)
+	}()
+
+	var errs []error
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("monitoring did not stop in time: %w", ctx.Err()))
+	}
 
-	var problematic []*Service
-	for i := range m.cfg.Services {
-		service := &m.cfg.Services[i]
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
-		if service.isProblematic() {
-			problematic = append(problematic, service)
+	m.saveSilences()
+	m.saveDynamicServices()
+	m.saveUptime()
+	if m.eventLog != nil {
+		if err := m.eventLog.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close event log: %w", err))
 		}
+		m.eventLog = nil
 	}
-	return problematic
+	return errors.Join(errs...)
 }
 
 func (m *Manager) handleProblematicServices(notificationManager *notification.Manager, targets notification.ProtocolTargets, services []*Service, problematicReportCooldown time.Duration) {
 	m.mutex.Lock()
 
-	now := time.Now()
-	var buf bytes.Buffer
+	now := m.clock.Now()
+	reports := newReportsByTargets(newProblemReport)
+	// reportedIn tracks the report each root cause ended up in, so its dependents can be listed alongside it.
+	reportedIn := make(map[string]*problemReport)
+
+	// Services that are down but not due are still considered as root causes of their dependents.
+	down := m.downServices()
 
-	reportedCount := 0
 	for _, service := range services {
-		service.LastProblem = now
-		problemDuration := time.Since(service.LastPulse)
-		overdue := problemDuration - service.HeartbeatTimeoutDuration
+		// The service was found to be due without holding the write lock, it might have pulsed since.
+		if !service.isProblematic() {
+			continue
+		}
+
+		problemDuration := now.Sub(service.LastPulse)
+		overdue := now.Sub(service.downSince())
+		m.openIncident(service, service.downSince())
+
+		if service.isFlapping() {
+			slog.Info("Leaving out problematic service from notification because it's flapping.", "service", service.Name, "flapping since", service.FlappingSince)
+			service.reportSuppressed = true
+			continue
+		}
+
+		if causes := m.rootCauses(service, down); len(causes) != 0 {
+			slog.Info("Leaving out problematic service from notification because it's likely caused by another service.", "service", service.Name, "likely caused by", causes)
+			service.reportSuppressed = true
+			continue
+		}
+
+		if reason, silenced := m.silencedBy(service, now); silenced {
+			slog.Info("Leaving out problematic service from notification because it's silenced.", "service", service.Name, "silenced by", reason)
+			service.reportSuppressed = true
+			continue
+		}
+
+		if service.Acknowledgement != nil && !service.Acknowledgement.isActive(now) {
+			slog.Info("Acknowledgement expired, resuming problematic reports", "service", service.Name)
+			service.Acknowledgement = nil
+		}
+
+		if service.Acknowledgement.isActive(now) {
+			slog.Info("Leaving out problematic service from notification because it has been acknowledged.", "service", service.Name, "comment", service.Acknowledgement.Comment)
+			service.reportSuppressed = true
+			continue
+		}
 
+		// Suppressed services are left unhandled, so they're reported as soon as the suppression ends. The others
+		// are looked at again after the poll frequency.
+		service.LastProblem = now
+		service.reportSuppressed = false
 		if service.isProblematicReportCooldownActive(problematicReportCooldown) {
 			cooldownEndTime := service.LastProblemReported.Add(problematicReportCooldown)
-			remainingCooldown := time.Until(cooldownEndTime)
+			remainingCooldown := cooldownEndTime.Sub(now)
 			slog.Info("Leaving out problematic service from notification because it's on report cooldown.", "service", service.Name, "remaining cooldown", remainingCooldown)
 		} else {
-			if reportedCount == 0 {
-				buf.WriteString("Service Name, Last Pulse, Problem Duration, Overdue\n")
-			}
-			reportedCount++
+			report := reports.forTargets(service.notificationTargets(m.cfg.Routes, targets))
 			service.LastProblemReported = now
-			if _, err := fmt.Fprintf(&buf, "%s, %s, %s, %s\n", service.Name, service.LastPulse.String(), problemDuration.String(), overdue.String()); err != nil {
-				slog.Error("Failed to write service data to buffer, notification will be missing this data", "service", service.Name, "error", err)
+			report.add(service, problemDuration, overdue)
+			reportedIn[service.Name] = report
+		}
+	}
+
+	// Dependents are listed next to every reported root cause, whether or not they were due themselves, since a
+	// root cause and its dependents rarely reach their deadlines at the same time.
+	for _, service := range m.services {
+		if !down[service.Name] {
+			continue
+		}
+		reported := false
+		for _, cause := range m.rootCauses(service, down) {
+			if report, ok := reportedIn[cause]; ok {
+				report.addDependent(service, cause)
+				reported = true
 			}
 		}
+		if reported {
+			m.openIncident(service, service.downSince())
+			service.LastProblem = now
+			service.LastProblemReported = now
+			service.reportSuppressed = false
+		}
 	}
 
 	m.mutex.Unlock()
 
 	slog.Info("Detected problematic", "services", services)
 
-	body := buf.String()
-	if reportedCount == 0 {
-		slog.Info("All problematic services are on report cooldown, skipping notification")
+	if len(reports.reports) == 0 {
+		slog.Info("No problematic service has to be reported, skipping notification")
 		return
 	}
 
-	data := notification.SendData{
-		Title: fmt.Sprintf("Problem detected with %d services", reportedCount),
-		Body:  body,
+	for _, report := range reports.reports {
+		m.sendReport(notificationManager, report.targets, report.services, notification.SendData{
+			Title: report.title(),
+			Body:  report.body(),
+		})
+	}
+}
+
+// recordNotification adds a notification event, including whether sending failed, to each of the services.
+func (m *Manager) recordNotification(services []*Service, title string, err error) {
+	message := title
+	if err != nil {
+		message = fmt.Sprintf("%s (failed: %v)", title, err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.clock.Now()
+	for _, service := range services {
+		m.recordEvent(service, EventNotification, now, message)
+	}
+}
+
+// silencedBy returns a description of the silence or maintenance window currently suppressing reports for the service.
+// Callers must hold the mutex.
+func (m *Manager) silencedBy(service *Service, now time.Time) (string, bool) {
+	reason, _, silenced := m.silencedUntil(service, now)
+	return reason, silenced
+}
+
+// silencedUntil is silencedBy, additionally returning when the silence or maintenance window ends. Callers must hold
+// the mutex.
+func (m *Manager) silencedUntil(service *Service, now time.Time) (string, time.Time, bool) {
+	for i := range m.cfg.MaintenanceWindows {
+		window := &m.cfg.MaintenanceWindows[i]
+		if !window.matches(service) {
+			continue
+		}
+		if end, active := window.activeUntil(now); active {
+			return "maintenance window " + window.Name, end, true
+		}
+	}
+
+	for _, silence := range m.silences {
+		if silence.matches(service) && silence.state(now) == SilenceStateActive {
+			return "silence " + silence.ID, silence.EndsAt, true
+		}
+	}
+
+	return "", time.Time{}, false
+}
+
+// suppressedUntil reports whether problem reports for a problematic service are held back and until when, the zero
+// time if the end isn't known, e.g. while one of its root causes is down. Callers must hold the mutex.
+func (m *Manager) suppressedUntil(service *Service, now time.Time, down map[string]bool) (time.Time, bool) {
+	if service.isFlapping() {
+		return service.flappingUntil(m.cfg.Flapping), true
+	}
+	if len(m.rootCauses(service, down)) != 0 {
+		return time.Time{}, true
+	}
+	if _, until, silenced := m.silencedUntil(service, now); silenced {
+		return until, true
+	}
+	if service.Acknowledgement.isActive(now) {
+		return service.Acknowledgement.ExpiresAt, true
+	}
+	return time.Time{}, false
+}
+
+// downServices tells for every service whether it's down, callers must hold the mutex.
+func (m *Manager) downServices() map[string]bool {
+	down := make(map[string]bool, len(m.services))
+	for _, service := range m.services {
+		down[service.Name] = service.isProblematic()
+	}
+	return down
+}
+
+// CreateSilence validates and stores a new silence. StartsAt defaults to now and, if EndsAt is zero, it's derived from duration.
+func (m *Manager) CreateSilence(silence Silence, duration time.Duration) (*Silence, error) {
+	now := m.clock.Now()
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if silence.EndsAt.IsZero() && duration > 0 {
+		silence.EndsAt = silence.StartsAt.Add(duration)
+	}
+
+	if len(silence.Services) == 0 && len(silence.Tags) == 0 {
+		return nil, fmt.Errorf("%w: no services or tags given", apperror.ErrInvalidSilence)
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return nil, fmt.Errorf("%w: must end after it starts", apperror.ErrInvalidSilence)
+	}
+	if !silence.EndsAt.After(now) {
+		return nil, fmt.Errorf("%w: end is in the past", apperror.ErrInvalidSilence)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, name := range silence.Services {
+		if _, exists := m.lookup[name]; !exists {
+			return nil, fmt.Errorf("%w: %s", apperror.ErrServiceNotFound, name)
+		}
+	}
+
+	silence.ID = newSilenceID()
+	silence.CreatedAt = now
+	silence.clock = m.clock
+	m.silences = append(m.silences, &silence)
+	m.saveSilences()
+
+	slog.Info("Silence created", "id", silence.ID, "services", silence.Services, "tags", silence.Tags, "starts_at", silence.StartsAt, "ends_at", silence.EndsAt)
+	created := silence
+	return &created, nil
+}
+
+func (m *Manager) ListSilences() []Silence {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	silences := make([]Silence, 0, len(m.silences))
+	for _, silence := range m.silences {
+		silences = append(silences, *silence)
+	}
+	return silences
+}
+
+// ExpireSilence ends a silence immediately while keeping it around for reference, use DeleteSilence to remove it.
+func (m *Manager) ExpireSilence(id string) (*Silence, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	index := slices.IndexFunc(m.silences, func(s *Silence) bool { return s.ID == id })
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %s", apperror.ErrSilenceNotFound, id)
+	}
+
+	now := m.clock.Now()
+	silence := m.silences[index]
+	if silence.state(now) != SilenceStateExpired {
+		silence.EndsAt = now
+		if silence.StartsAt.After(now) {
+			silence.StartsAt = now
+		}
+		m.saveSilences()
+		slog.Info("Silence expired", "id", id)
+	}
+
+	expired := *silence
+	return &expired, nil
+}
+
+func (m *Manager) DeleteSilence(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	index := slices.IndexFunc(m.silences, func(s *Silence) bool { return s.ID == id })
+	if index < 0 {
+		return fmt.Errorf("%w: %s", apperror.ErrSilenceNotFound, id)
+	}
+
+	m.silences = slices.Delete(m.silences, index, index+1)
+	m.saveSilences()
+	slog.Info("Silence deleted", "id", id)
+	return nil
+}
+
+func (m *Manager) silencesPath() string {
+	return filepath.Join(m.cfg.StateDirectory, "silences.json")
+}
+
+// saveSilences persists the silences if a state directory is configured, callers must hold the mutex.
+func (m *Manager) saveSilences() {
+	if len(m.cfg.StateDirectory) == 0 {
+		return
 	}
 
-	if err := notificationManager.SendWithFallback(targets, data); err != nil {
-		slog.Error("Failed to send notification - monitoring may be compromised", "error", err)
+	if err := store.Save(m.silencesPath(), m.silences); err != nil {
+		slog.Error("Failed to persist silences, they will not survive a restart", "path", m.silencesPath(), "error", err)
 	}
 }
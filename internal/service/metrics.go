@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+)
+
+const DefaultMetricRuleSamples = 10
+
+// MetricRule raises an incident when a metric sent along with a pulse is below Min, above Max or deviates from the
+// moving average of the previous Samples values by more than MaxDeviation, a fraction of the average.
+// Pulses that don't carry the metric are not checked.
+type MetricRule struct {
+	Metric       string   `yaml:"metric" json:"metric"`
+	Min          *float64 `yaml:"min" json:"min,omitempty"`
+	Max          *float64 `yaml:"max" json:"max,omitempty"`
+	MaxDeviation float64  `yaml:"max_deviation" json:"max_deviation,omitempty"`
+	Samples      int      `yaml:"samples" json:"samples,omitempty"`
+}
+
+func (r *MetricRule) validate() error {
+	if len(r.Metric) == 0 {
+		return fmt.Errorf("%w: rule is missing a metric", apperror.ErrInvalidMetricRule)
+	}
+	if r.Min == nil && r.Max == nil && r.MaxDeviation == 0 {
+		return fmt.Errorf("%w: %s needs at least one of min, max or max_deviation", apperror.ErrInvalidMetricRule, r.Metric)
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("%w: %s has a min greater than its max", apperror.ErrInvalidMetricRule, r.Metric)
+	}
+	if r.MaxDeviation < 0 {
+		return fmt.Errorf("%w: %s: max_deviation can't be negative: %v", apperror.ErrInvalidMetricRule, r.Metric, r.MaxDeviation)
+	}
+	if r.Samples < 0 {
+		return fmt.Errorf("%w: %s: samples can't be negative: %d", apperror.ErrInvalidMetricRule, r.Metric, r.Samples)
+	}
+	return nil
+}
+
+func (r *MetricRule) samples() int {
+	if r.Samples == 0 {
+		return DefaultMetricRuleSamples
+	}
+	return r.Samples
+}
+
+// check returns why the value violates the rule, history holds the previous values from oldest to newest.
+// The deviation is only checked once enough samples were collected.
+func (r *MetricRule) check(value float64, history []float64) (string, bool) {
+	if r.Min != nil && value < *r.Min {
+		return fmt.Sprintf("%s is %v, below the minimum of %v", r.Metric, value, *r.Min), true
+	}
+	if r.Max != nil && value > *r.Max {
+		return fmt.Sprintf("%s is %v, above the maximum of %v", r.Metric, value, *r.Max), true
+	}
+
+	if r.MaxDeviation == 0 || len(history) < r.samples() {
+		return "", false
+	}
+
+	var sum float64
+	for _, v := range history[len(history)-r.samples():] {
+		sum += v
+	}
+	average := sum / float64(r.samples())
+	if average == 0 {
+		return "", false
+	}
+
+	deviation := math.Abs(value-average) / math.Abs(average)
+	if deviation > r.MaxDeviation {
+		return fmt.Sprintf("%s is %v, %.0f%% off the average of %.4g", r.Metric, value, deviation*100, average), true
+	}
+	return "", false
+}
+
+func (s *Service) validateMetricRules() error {
+	for i := range s.MetricRules {
+		if err := s.MetricRules[i].validate(); err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// recordMetrics checks the metrics of a pulse against the rules of the service and stores them. A violation marks
+// the service as problematic until a later pulse carrying the metrics passes every rule, pulses without any checked
+// metric leave it unchanged. It returns the violations caused by these metrics.
+func (s *Service) recordMetrics(at time.Time, metrics map[string]float64) []string {
+	var violations []string
+	checked := false
+	for i := range s.MetricRules {
+		rule := &s.MetricRules[i]
+		value, ok := metrics[rule.Metric]
+		if !ok {
+			continue
+		}
+		checked = true
+		if reason, violated := rule.check(value, s.metricHistory[rule.Metric]); violated {
+			violations = append(violations, reason)
+		}
+	}
+
+	if len(metrics) != 0 {
+		s.LastMetrics = metrics
+		if s.metricHistory == nil {
+			s.metricHistory = make(map[string][]float64)
+		}
+		for name, value := range metrics {
+			size := s.metricHistorySize(name)
+			if size == 0 {
+				continue
+			}
+			history := append(s.metricHistory[name], value)
+			s.metricHistory[name] = history[max(len(history)-size, 0):]
+		}
+	}
+
+	if !checked {
+		return nil
+	}
+	if len(violations) == 0 {
+		s.metricViolations = nil
+		s.metricViolationSince = time.Time{}
+		return nil
+	}
+
+	if s.metricViolationSince.IsZero() {
+		s.metricViolationSince = at
+	}
+	s.metricViolations = violations
+	return violations
+}
+
+// metricHistorySize is the number of values kept for a metric, enough for the moving average of every rule checking it.
+func (s *Service) metricHistorySize(metric string) int {
+	size := 0
+	for i := range s.MetricRules {
+		if s.MetricRules[i].Metric == metric {
+			size = max(size, s.MetricRules[i].samples())
+		}
+	}
+	return size
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+)
+
+// validatePulsePolicy checks the optional policies deciding when a service is down. MissedIntervals tolerates that
+// many heartbeat timeouts without a pulse, MinPulses requires at least that many pulses within MinPulsesWindow.
+func (s *Service) validatePulsePolicy() error {
+	if s.MissedIntervals < 0 {
+		return fmt.Errorf("%w: %s: missed_intervals can't be negative: %d", apperror.ErrInvalidPulsePolicy, s.Name, s.MissedIntervals)
+	}
+	if s.MinPulses < 0 {
+		return fmt.Errorf("%w: %s: min_pulses can't be negative: %d", apperror.ErrInvalidPulsePolicy, s.Name, s.MinPulses)
+	}
+	if s.MinPulses > 0 && s.MinPulsesWindow <= 0 {
+		return fmt.Errorf("%w: %s: min_pulses requires a positive min_pulses_window", apperror.ErrInvalidPulsePolicy, s.Name)
+	}
+	return nil
+}
+
+// timeout is how long the service may go without a pulse, the heartbeat timeout for every missed interval tolerated.
+func (s *Service) timeout() time.Duration {
+	return time.Duration(max(s.MissedIntervals, 1)) * s.HeartbeatTimeoutDuration
+}
+
+// recordPulse adds a pulse to the history used by the min pulses policy, pulses that left the window are dropped.
+func (s *Service) recordPulse(at time.Time) {
+	if s.MinPulses == 0 {
+		return
+	}
+
+	s.pulses = append(s.pulses, at)
+	cutoff := at.Add(-s.MinPulsesWindow)
+	s.pulses = slices.DeleteFunc(s.pulses, func(t time.Time) bool { return !t.After(cutoff) })
+}
+
+// resetPulseHistory starts the pulse history over, the service gets a full window before the min pulses policy applies.
+func (s *Service) resetPulseHistory(at time.Time) {
+	s.pulses = nil
+	s.pulseHistorySince = at
+}
+
+// minPulsesDeadline returns when fewer than MinPulses pulses will be left within the window unless the service
+// pulses again.
+func (s *Service) minPulsesDeadline() time.Time {
+	if len(s.pulses) < s.MinPulses {
+		return s.pulseHistorySince.Add(s.MinPulsesWindow)
+	}
+	return s.pulses[len(s.pulses)-s.MinPulses].Add(s.MinPulsesWindow)
+}
+
+func (s *Service) recentPulses(now time.Time) int {
+	cutoff := now.Add(-s.MinPulsesWindow)
+	count := 0
+	for _, pulse := range s.pulses {
+		if pulse.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/internal/store"
+)
+
+// ServiceDefinition describes a service registered at runtime over the API, it's also the format dynamic
+// services are persisted in.
+type ServiceDefinition struct {
+	Name                     string       `json:"name"`
+	HeartbeatTimeoutDuration string       `json:"heartbeat_timeout_duration"`
+	Notifiers                []string     `json:"notifiers,omitempty"`
+	Tags                     []string     `json:"tags,omitempty"`
+	Group                    string       `json:"group,omitempty"`
+	DependsOn                []string     `json:"depends_on,omitempty"`
+	WarningThreshold         float64      `json:"warning_threshold,omitempty"`
+	WarningNotifiers         []string     `json:"warning_notifiers,omitempty"`
+	MissedIntervals          int          `json:"missed_intervals,omitempty"`
+	MinPulses                int          `json:"min_pulses,omitempty"`
+	MinPulsesWindow          string       `json:"min_pulses_window,omitempty"`
+	MetricRules              []MetricRule `json:"metric_rules,omitempty"`
+}
+
+func (d *ServiceDefinition) toService() (*Service, error) {
+	timeout, err := time.ParseDuration(d.HeartbeatTimeoutDuration)
+	if err != nil {
+		return nil, fmt.Errorf("%w: heartbeat_timeout_duration: %w", apperror.ErrInvalidServiceDefinition, err)
+	}
+
+	var minPulsesWindow time.Duration
+	if len(d.MinPulsesWindow) != 0 {
+		if minPulsesWindow, err = time.ParseDuration(d.MinPulsesWindow); err != nil {
+			return nil, fmt.Errorf("%w: min_pulses_window: %w", apperror.ErrInvalidServiceDefinition, err)
+		}
+	}
+
+	service := &Service{
+		Name:                     d.Name,
+		HeartbeatTimeoutDuration: timeout,
+		Notifiers:                d.Notifiers,
+		Tags:                     d.Tags,
+		Group:                    d.Group,
+		DependsOn:                d.DependsOn,
+		WarningThreshold:         d.WarningThreshold,
+		WarningNotifiers:         d.WarningNotifiers,
+		MissedIntervals:          d.MissedIntervals,
+		MinPulses:                d.MinPulses,
+		MinPulsesWindow:          minPulsesWindow,
+		MetricRules:              d.MetricRules,
+		Dynamic:                  true,
+	}
+	if err := service.validate(); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (s *Service) definition() ServiceDefinition {
+	def := ServiceDefinition{
+		Name:                     s.Name,
+		HeartbeatTimeoutDuration: s.HeartbeatTimeoutDuration.String(),
+		Notifiers:                s.Notifiers,
+		Tags:                     s.Tags,
+		Group:                    s.Group,
+		DependsOn:                s.DependsOn,
+		WarningThreshold:         s.WarningThreshold,
+		WarningNotifiers:         s.WarningNotifiers,
+		MissedIntervals:          s.MissedIntervals,
+		MinPulses:                s.MinPulses,
+		MetricRules:              s.MetricRules,
+	}
+	if s.MinPulsesWindow > 0 {
+		def.MinPulsesWindow = s.MinPulsesWindow.String()
+	}
+	return def
+}
+
+// ProvisionTemplate creates a dynamic service the first time an unknown service whose name matches Pattern pulses.
+// Pattern uses the syntax of path.Match, e.g. "ci-*".
+type ProvisionTemplate struct {
+	Pattern                  string        `yaml:"pattern"`
+	HeartbeatTimeoutDuration time.Duration `yaml:"heartbeat_timeout_duration"`
+	Notifiers                []string      `yaml:"notifiers"`
+	Tags                     []string      `yaml:"tags"`
+	Group                    string        `yaml:"group"`
+	WarningThreshold         float64       `yaml:"warning_threshold"`
+	WarningNotifiers         []string      `yaml:"warning_notifiers"`
+	MissedIntervals          int           `yaml:"missed_intervals"`
+	MinPulses                int           `yaml:"min_pulses"`
+	MinPulsesWindow          time.Duration `yaml:"min_pulses_window"`
+	MetricRules              []MetricRule  `yaml:"metric_rules"`
+}
+
+func (p *ProvisionTemplate) validate() error {
+	if _, err := path.Match(p.Pattern, ""); err != nil || len(p.Pattern) == 0 {
+		return fmt.Errorf("%w: invalid pattern %q", apperror.ErrInvalidProvisionTemplate, p.Pattern)
+	}
+
+	// Names are validated when provisioning, a valid placeholder stands in for them so every other field is checked
+	// whatever the shape of the pattern.
+	template := Service{
+		Name:                     "provisioned",
+		HeartbeatTimeoutDuration: p.HeartbeatTimeoutDuration,
+		WarningThreshold:         p.WarningThreshold,
+		MissedIntervals:          p.MissedIntervals,
+		MinPulses:                p.MinPulses,
+		MinPulsesWindow:          p.MinPulsesWindow,
+		MetricRules:              p.MetricRules,
+	}
+	if err := template.validate(); err != nil {
+		return fmt.Errorf("%w: %s: %w", apperror.ErrInvalidProvisionTemplate, p.Pattern, err)
+	}
+
+	return nil
+}
+
+func (p *ProvisionTemplate) matches(name string) bool {
+	matched, _ := path.Match(p.Pattern, name)
+	return matched
+}
+
+// provision creates a dynamic service from the first matching auto provisioning template, callers must hold the mutex.
+func (m *Manager) provision(name string) (*Service, bool) {
+	index := slices.IndexFunc(m.cfg.AutoProvision, func(p ProvisionTemplate) bool { return p.matches(name) })
+	if index < 0 {
+		return nil, false
+	}
+
+	template := &m.cfg.AutoProvision[index]
+	service := &Service{
+		Name:                     name,
+		HeartbeatTimeoutDuration: template.HeartbeatTimeoutDuration,
+		Notifiers:                slices.Clone(template.Notifiers),
+		Tags:                     slices.Clone(template.Tags),
+		Group:                    template.Group,
+		WarningThreshold:         template.WarningThreshold,
+		WarningNotifiers:         slices.Clone(template.WarningNotifiers),
+		MissedIntervals:          template.MissedIntervals,
+		MinPulses:                template.MinPulses,
+		MinPulsesWindow:          template.MinPulsesWindow,
+		MetricRules:              slices.Clone(template.MetricRules),
+		Dynamic:                  true,
+	}
+	if err := service.validate(); err != nil {
+		slog.Warn("Refusing to auto provision service", "service", name, "pattern", template.Pattern, "error", err)
+		return nil, false
+	}
+
+	service.LastPulse = m.clock.Now()
+	m.addService(service)
+	m.saveDynamicServices()
+
+	slog.Info("Auto provisioned service", "service", name, "pattern", template.Pattern)
+	return service, true
+}
+
+// CreateService registers a new dynamic service, its heartbeat timeout starts counting immediately. The definition
+// of the created service is returned, the service itself is only safe to read while holding the mutex.
+func (m *Manager) CreateService(def ServiceDefinition) (ServiceDefinition, error) {
+	service, err := def.toService()
+	if err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.lookup[service.Name]; exists {
+		return ServiceDefinition{}, fmt.Errorf("%w: %s", apperror.ErrDuplicateServiceNames, service.Name)
+	}
+	if err := validateDependencies(append(slices.Clone(m.services), service)); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	service.LastPulse = m.clock.Now()
+	m.addService(service)
+	m.saveDynamicServices()
+
+	slog.Info("Dynamic service created", "service", service.Name, "heartbeat_timeout_duration", service.HeartbeatTimeoutDuration)
+	return service.definition(), nil
+}
+
+// UpdateService replaces the settings of a dynamic service while keeping its runtime state, the name can't be changed.
+// Like CreateService it returns the definition of the updated service.
+func (m *Manager) UpdateService(name string, def ServiceDefinition) (ServiceDefinition, error) {
+	if len(def.Name) == 0 {
+		def.Name = name
+	}
+	if def.Name != name {
+		return ServiceDefinition{}, fmt.Errorf("%w: renaming is not supported (%s -> %s)", apperror.ErrInvalidServiceDefinition, name, def.Name)
+	}
+
+	updated, err := def.toService()
+	if err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	service, err := m.dynamicService(name)
+	if err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	candidates := slices.Clone(m.services)
+	candidates[slices.Index(candidates, service)] = updated
+	if err := validateDependencies(candidates); err != nil {
+		return ServiceDefinition{}, err
+	}
+
+	service.applyConfig(updated)
+	m.saveDynamicServices()
+	m.wakeUp()
+
+	slog.Info("Dynamic service updated", "service", name, "heartbeat_timeout_duration", service.HeartbeatTimeoutDuration)
+	return service.definition(), nil
+}
+
+func (m *Manager) DeleteService(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	service, err := m.dynamicService(name)
+	if err != nil {
+		return err
+	}
+	for _, other := range m.services {
+		if slices.Contains(other.DependsOn, name) {
+			return fmt.Errorf("%w: %s is depended on by %s", apperror.ErrServiceHasDependents, name, other.Name)
+		}
+	}
+
+	delete(m.lookup, name)
+	m.services = slices.DeleteFunc(m.services, func(s *Service) bool { return s == service })
+	m.saveDynamicServices()
+
+	slog.Info("Dynamic service deleted", "service", name)
+	return nil
+}
+
+// dynamicService looks up a service that may be modified at runtime, callers must hold the mutex.
+func (m *Manager) dynamicService(name string) (*Service, error) {
+	service, exists := m.lookup[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", apperror.ErrServiceNotFound, name)
+	}
+	if !service.Dynamic {
+		return nil, fmt.Errorf("%w: %s", apperror.ErrStaticService, name)
+	}
+	return service, nil
+}
+
+// addService registers a service with the manager, callers must hold the mutex.
+func (m *Manager) addService(service *Service) {
+	service.clock = m.clock
+	if service.MonitoredSince.IsZero() {
+		service.MonitoredSince = m.clock.Now()
+	}
+	if service.pulseHistorySince.IsZero() {
+		service.resetPulseHistory(m.clock.Now())
+	}
+	m.lookup[service.Name] = service
+	m.services = append(m.services, service)
+	m.wakeUp()
+}
+
+func (m *Manager) dynamicServicesPath() string {
+	return filepath.Join(m.cfg.StateDirectory, "services.json")
+}
+
+func (m *Manager) loadDynamicServices(now time.Time) error {
+	var definitions []ServiceDefinition
+	if err := store.Load(m.dynamicServicesPath(), &definitions); err != nil {
+		return err
+	}
+
+	for _, def := range definitions {
+		service, err := def.toService()
+		if err != nil {
+			return err
+		}
+		if _, exists := m.lookup[service.Name]; exists {
+			slog.Warn("Dynamic service is shadowed by a service in the config, ignoring it", "service", service.Name)
+			continue
+		}
+
+		service.LastPulse = now
+		m.addService(service)
+	}
+
+	if err := validateDependencies(m.services); err != nil {
+		slog.Warn("Restored dynamic services have invalid dependencies, root cause detection may be incomplete", "error", err)
+	}
+
+	return nil
+}
+
+// saveDynamicServices persists the dynamic services if a state directory is configured, callers must hold the mutex.
+func (m *Manager) saveDynamicServices() {
+	if len(m.cfg.StateDirectory) == 0 {
+		return
+	}
+
+	definitions := make([]ServiceDefinition, 0, len(m.services))
+	for _, service := range m.services {
+		if service.Dynamic {
+			definitions = append(definitions, service.definition())
+		}
+	}
+
+	if err := store.Save(m.dynamicServicesPath(), definitions); err != nil {
+		slog.Error("Failed to persist dynamic services, they will not survive a restart", "path", m.dynamicServicesPath(), "error", err)
+	}
+}
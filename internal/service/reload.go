@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+)
+
+// Reload applies a changed config. Services that are still configured keep their runtime state, such as the last
+// pulse and ongoing incidents, and take over their new settings. New services start out as if they just pulsed and
+// removed ones are dropped together with their history. Dynamic services are kept as they are.
+//
+// Nothing changes if the config conflicts with the dynamic services. The state directory and event persistence
+// can't be changed without a restart, their previous settings are kept.
+func (m *Manager) Reload(cfg *Config, instr MonitoringInstructions) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if cfg.StateDirectory != m.cfg.StateDirectory || cfg.Events.Persist != m.cfg.Events.Persist {
+		slog.Warn("Changing the state directory or event persistence requires a restart, keeping the previous settings")
+		cfg.StateDirectory = m.cfg.StateDirectory
+		cfg.Events.Persist = m.cfg.Events.Persist
+	}
+
+	// Validate against the new settings before touching any service, so a failed reload leaves everything as it was.
+	candidates := make([]*Service, 0, len(cfg.Services)+len(m.services))
+	names := make(map[string]struct{}, len(cfg.Services))
+	for i := range cfg.Services {
+		configured := &cfg.Services[i]
+		if _, ok := names[configured.Name]; ok {
+			return apperror.ErrDuplicateServiceNames
+		}
+		names[configured.Name] = struct{}{}
+		if existing, ok := m.lookup[configured.Name]; ok && existing.Dynamic {
+			return fmt.Errorf("%w: %s is already registered as a dynamic service", apperror.ErrDuplicateServiceNames, configured.Name)
+		}
+		candidates = append(candidates, configured)
+	}
+	for _, service := range m.services {
+		if service.Dynamic {
+			candidates = append(candidates, service)
+		}
+	}
+	if err := validateDependencies(candidates); err != nil {
+		return err
+	}
+
+	now := m.clock.Now()
+	services := make([]*Service, 0, len(candidates))
+	lookup := make(map[string]*Service, len(candidates))
+	var added, kept []string
+	for _, candidate := range candidates {
+		service := candidate
+		if existing, ok := m.lookup[candidate.Name]; ok {
+			if !existing.Dynamic {
+				existing.applyConfig(candidate)
+				kept = append(kept, candidate.Name)
+			}
+			service = existing
+		} else {
+			service.clock = m.clock
+			service.LastPulse = now
+			service.MonitoredSince = now
+			service.resetPulseHistory(now)
+			added = append(added, service.Name)
+		}
+		// The flapping settings might have changed, a service that is no longer flapping by them must not stay
+		// excluded from problem reports.
+		service.updateFlapping(now, cfg.Flapping)
+		services = append(services, service)
+		lookup[service.Name] = service
+	}
+
+	var removed []string
+	for _, service := range m.services {
+		if _, ok := lookup[service.Name]; !ok {
+			removed = append(removed, service.Name)
+		}
+	}
+
+	m.cfg = cfg
+	m.services = services
+	m.lookup = lookup
+	m.instr = instr
+	m.saveUptime()
+	m.wakeUp()
+	select {
+	case m.summaryWake <- struct{}{}:
+	default:
+	}
+
+	slog.Info("Config reloaded", "added", added, "kept", kept, "removed", removed)
+	return nil
+}
+
+// instructions returns the current monitoring instructions.
+func (m *Manager) instructions() MonitoringInstructions {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.instr
+}
+
+// nextSummary returns when the summary report following the previous one is due by the current settings.
+func (m *Manager) nextSummary() time.Time {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.cfg.SummaryReport.next(m.lastSummary, m.instr.Timings.SuccessfulReportCooldown)
+}
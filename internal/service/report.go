@@ -0,0 +1,169 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"service-uptime-center/notification"
+)
+
+// reportsByTargets groups reports by the primary notifiers of their targets, so services sharing them end up in a
+// single notification. Reports are kept in the order they were first needed in.
+type reportsByTargets[R any] struct {
+	reports []R
+	lookup  map[string]R
+	create  func(targets notification.ProtocolTargets) R
+}
+
+func newReportsByTargets[R any](create func(targets notification.ProtocolTargets) R) *reportsByTargets[R] {
+	return &reportsByTargets[R]{
+		lookup: make(map[string]R),
+		create: create,
+	}
+}
+
+// forTargets returns the report sent to targets, creating it if needed.
+func (g *reportsByTargets[R]) forTargets(targets notification.ProtocolTargets) R {
+	key := strings.Join(targets.Primary, ",")
+	report, ok := g.lookup[key]
+	if !ok {
+		report = g.create(targets)
+		g.lookup[key] = report
+		g.reports = append(g.reports, report)
+	}
+	return report
+}
+
+// sendReport sends a notification and records it on the services it mentions, callers must not hold the mutex.
+func (m *Manager) sendReport(notificationManager *notification.Manager, targets notification.ProtocolTargets, services []*Service, data notification.SendData) {
+	err := notificationManager.SendWithFallback(targets, data)
+	if err != nil {
+		slog.Error("Failed to send notification - monitoring may be compromised", "error", err)
+	}
+	m.recordNotification(services, data.Title, err)
+}
+
+// lineReport lists one line per service, it's used for the notifications that aren't about problems.
+type lineReport struct {
+	targets  notification.ProtocolTargets
+	lines    []string
+	services []*Service
+}
+
+func newLineReport(targets notification.ProtocolTargets) *lineReport {
+	return &lineReport{targets: targets}
+}
+
+func (r *lineReport) add(service *Service, line string) {
+	r.services = append(r.services, service)
+	r.lines = append(r.lines, line)
+}
+
+func (r *lineReport) body(heading string) string {
+	var b strings.Builder
+	b.WriteString(heading)
+	writeLines(&b, r.lines)
+	return b.String()
+}
+
+// problemReport collects the problematic services that are reported to the same notification targets.
+type problemReport struct {
+	targets notification.ProtocolTargets
+	// lines holds one line per service, keyed by group, ungrouped services use the empty key.
+	lines map[string][]string
+	// dependents maps a reported service to the problematic services that are likely down because of it.
+	dependents map[string][]string
+	// services holds every service mentioned in the report, including dependents.
+	services []*Service
+	count    int
+}
+
+func newProblemReport(targets notification.ProtocolTargets) *problemReport {
+	return &problemReport{
+		targets:    targets,
+		lines:      make(map[string][]string),
+		dependents: make(map[string][]string),
+	}
+}
+
+func (r *problemReport) add(service *Service, problemDuration time.Duration, overdue time.Duration) {
+	r.count++
+	r.services = append(r.services, service)
+	line := fmt.Sprintf("%s, %s, %s, %s", service.Name, service.LastPulse.String(), problemDuration.String(), overdue.String())
+	if reasons := service.problemReasons(); len(reasons) != 0 {
+		line += fmt.Sprintf(" (%s)", strings.Join(reasons, "; "))
+	}
+	r.lines[service.Group] = append(r.lines[service.Group], line)
+}
+
+func (r *problemReport) addDependent(service *Service, cause string) {
+	r.dependents[cause] = append(r.dependents[cause], service.Name)
+	if !slices.Contains(r.services, service) {
+		r.services = append(r.services, service)
+	}
+}
+
+func (r *problemReport) title() string {
+	title := fmt.Sprintf("Problem detected with %d services", r.count)
+	if groups := r.groups(); len(groups) != 0 {
+		title += fmt.Sprintf(" (%s)", strings.Join(groups, ", "))
+	}
+	return title
+}
+
+// body lists the services under a heading per group, the headings are left out if no service has a group.
+// Services suppressed because of a dependency are listed last, next to their likely cause.
+func (r *problemReport) body() string {
+	var b strings.Builder
+	b.WriteString("Service Name, Last Pulse, Problem Duration, Overdue\n")
+
+	groups := r.groups()
+	if len(groups) == 0 {
+		writeLines(&b, r.lines[""])
+	} else {
+		if _, ok := r.lines[""]; ok {
+			groups = append(groups, "")
+		}
+		for _, group := range groups {
+			heading := group
+			if len(heading) == 0 {
+				heading = "ungrouped"
+			}
+			fmt.Fprintf(&b, "\n%s (%d):\n", heading, len(r.lines[group]))
+			writeLines(&b, r.lines[group])
+		}
+	}
+
+	causes := make([]string, 0, len(r.dependents))
+	for cause := range r.dependents {
+		causes = append(causes, cause)
+	}
+	slices.Sort(causes)
+	for _, cause := range causes {
+		fmt.Fprintf(&b, "\nLikely caused by %s: %s\n", cause, strings.Join(slices.Sorted(slices.Values(r.dependents[cause])), ", "))
+	}
+
+	return b.String()
+}
+
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// groups returns the sorted names of the groups in the report, excluding ungrouped services.
+func (r *problemReport) groups() []string {
+	var groups []string
+	for group := range r.lines {
+		if len(group) != 0 {
+			groups = append(groups, group)
+		}
+	}
+	slices.Sort(groups)
+	return groups
+}
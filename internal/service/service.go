@@ -4,16 +4,110 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
+
+	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/internal/clock"
+	"service-uptime-center/notification"
 )
 
 type Service struct {
 	Name                     string        `yaml:"name"`
 	HeartbeatTimeoutDuration time.Duration `yaml:"heartbeat_timeout_duration"`
-	LastPulse                time.Time
-	LastProblem              time.Time
-	LastProblemReported      time.Time
-	LastSuccessReport        time.Time
+	Notifiers                []string      `yaml:"notifiers"`
+	Tags                     []string      `yaml:"tags"`
+	Group                    string        `yaml:"group"`
+	DependsOn                []string      `yaml:"depends_on"`
+	WarningThreshold         float64       `yaml:"warning_threshold"`
+	WarningNotifiers         []string      `yaml:"warning_notifiers"`
+	MissedIntervals          int           `yaml:"missed_intervals"`
+	MinPulses                int           `yaml:"min_pulses"`
+	MinPulsesWindow          time.Duration `yaml:"min_pulses_window"`
+	MetricRules              []MetricRule  `yaml:"metric_rules"`
+	// The runtime state below is never read from the config.
+	LastPulse           time.Time          `yaml:"-"`
+	LastMetrics         map[string]float64 `yaml:"-"`
+	LastProblem         time.Time          `yaml:"-"`
+	LastProblemReported time.Time          `yaml:"-"`
+	LastSuccessReport   time.Time          `yaml:"-"`
+	Acknowledgement     *Acknowledgement   `yaml:"-"`
+	Pause               *Pause             `yaml:"-"`
+	IncidentStart       time.Time          `yaml:"-"`
+	FlappingSince       time.Time          `yaml:"-"`
+	MonitoredSince      time.Time          `yaml:"-"`
+	// Dynamic is set for services registered over the API, they are persisted in the state directory instead of the config.
+	Dynamic bool `yaml:"-"`
+
+	// transitions holds the times the service changed between healthy and problematic within the flapping window.
+	transitions      []time.Time
+	flappingNotified bool
+	// reportSuppressed is set while problem reports are held back, the service is due once that ends.
+	reportSuppressed bool
+	lateNotified     bool
+	history          *eventHistory
+	// incidents holds the resolved incidents within the longest uptime window.
+	incidents []Incident
+	// pulses holds the pulses within the min pulses window, tracked since pulseHistorySince.
+	pulses            []time.Time
+	pulseHistorySince time.Time
+	// metricHistory holds the latest values of every metric checked by a rule, oldest first.
+	metricHistory        map[string][]float64
+	metricViolations     []string
+	metricViolationSince time.Time
+	// checks holds the state of every active check of the service by check ID.
+	checks map[string]*checkState
+	// clock is set by the manager, services without one use the wall clock.
+	clock clock.Clock
+}
+
+// Pause excludes a service from monitoring until it's resumed, or until its next pulse when AutoResume is set.
+type Pause struct {
+	Comment    string
+	AutoResume bool
+	CreatedAt  time.Time
+}
+
+func (p *Pause) MarshalJSON() ([]byte, error) {
+	result := map[string]any{
+		"paused_at":   p.CreatedAt.Format(time.RFC3339),
+		"auto_resume": p.AutoResume,
+	}
+
+	if len(p.Comment) != 0 {
+		result["comment"] = p.Comment
+	}
+
+	return json.Marshal(result)
+}
+
+type Acknowledgement struct {
+	Comment   string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (a *Acknowledgement) MarshalJSON() ([]byte, error) {
+	result := map[string]any{
+		"acknowledged_at": a.CreatedAt.Format(time.RFC3339),
+	}
+
+	if len(a.Comment) != 0 {
+		result["comment"] = a.Comment
+	}
+	if !a.ExpiresAt.IsZero() {
+		result["expires_at"] = a.ExpiresAt.Format(time.RFC3339)
+	}
+
+	return json.Marshal(result)
+}
+
+// isActive reports whether the acknowledgement still suppresses reports, a nil acknowledgement is never active.
+func (a *Acknowledgement) isActive(now time.Time) bool {
+	if a == nil {
+		return false
+	}
+	return a.ExpiresAt.IsZero() || now.Before(a.ExpiresAt)
 }
 
 func (s *Service) String() string {
@@ -23,7 +117,10 @@ func (s *Service) String() string {
 func (s *Service) MarshalJSON() ([]byte, error) {
 	result := map[string]any{
 		"name":                       s.Name,
+		"state":                      s.state(s.now()),
 		"is_problematic":             s.isProblematic(),
+		"is_acknowledged":            s.Acknowledgement.isActive(s.now()),
+		"is_paused":                  s.Pause != nil,
 		"heartbeat_timeout_duration": s.HeartbeatTimeoutDuration.String(),
 	}
 
@@ -39,14 +136,210 @@ func (s *Service) MarshalJSON() ([]byte, error) {
 	if !s.LastSuccessReport.IsZero() {
 		result["last_success_report"] = s.LastSuccessReport.Format(time.RFC3339)
 	}
+	if s.Acknowledgement != nil {
+		result["acknowledgement"] = s.Acknowledgement
+	}
+	if s.Pause != nil {
+		result["pause"] = s.Pause
+	}
+	if s.Dynamic {
+		result["dynamic"] = true
+	}
+	if len(s.Notifiers) != 0 {
+		result["notifiers"] = s.Notifiers
+	}
+	if len(s.Tags) != 0 {
+		result["tags"] = s.Tags
+	}
+	if len(s.Group) != 0 {
+		result["group"] = s.Group
+	}
+	if len(s.DependsOn) != 0 {
+		result["depends_on"] = s.DependsOn
+	}
+	if s.WarningThreshold > 0 {
+		result["warning_threshold"] = s.WarningThreshold
+	}
+	if len(s.WarningNotifiers) != 0 {
+		result["warning_notifiers"] = s.WarningNotifiers
+	}
+	if s.MissedIntervals > 0 {
+		result["missed_intervals"] = s.MissedIntervals
+	}
+	if s.MinPulses > 0 {
+		result["min_pulses"] = s.MinPulses
+		result["min_pulses_window"] = s.MinPulsesWindow.String()
+		result["recent_pulses"] = s.recentPulses(s.now())
+	}
+	if len(s.MetricRules) != 0 {
+		result["metric_rules"] = s.MetricRules
+	}
+	if len(s.LastMetrics) != 0 {
+		result["metrics"] = s.LastMetrics
+	}
+	if len(s.metricViolations) != 0 {
+		result["metric_violations"] = s.metricViolations
+	}
+	if failures := s.checkMessages(func(state *checkState) string { return state.failure }); len(failures) != 0 {
+		result["check_failures"] = failures
+	}
+	if warnings := s.checkMessages(func(state *checkState) string { return state.warning }); len(warnings) != 0 {
+		result["check_warnings"] = warnings
+	}
+	if statuses := s.checkMessages(func(state *checkState) string { return state.status }); len(statuses) != 0 {
+		result["check_status"] = statuses
+	}
+	if !s.IncidentStart.IsZero() {
+		result["incident_start"] = s.IncidentStart.Format(time.RFC3339)
+	}
+	if s.isFlapping() {
+		result["is_flapping"] = true
+		result["flapping_since"] = s.FlappingSince.Format(time.RFC3339)
+	}
+	if !s.MonitoredSince.IsZero() {
+		result["monitored_since"] = s.MonitoredSince.Format(time.RFC3339)
+		result["uptime"] = s.uptimeWindows(s.now())
+	}
 
 	return json.Marshal(result)
 }
 
+func (s *Service) validate() error {
+	const MinHeartbeatFreq = time.Second * 60
+	if s.HeartbeatTimeoutDuration < MinHeartbeatFreq {
+		return fmt.Errorf("%w (min: %v): %v", apperror.ErrHeartbeatTimeoutTooShort, MinHeartbeatFreq, s.HeartbeatTimeoutDuration)
+	}
+
+	const MinNameLen = 2
+	const MaxNameLen = 64
+	if len(s.Name) < MinNameLen || len(s.Name) > MaxNameLen {
+		return fmt.Errorf("%w (min: %d, max: %d): %s", apperror.ErrInvalidServiceName, MinNameLen, MaxNameLen, s.Name)
+	}
+
+	if s.WarningThreshold < 0 || s.WarningThreshold >= 1 {
+		return fmt.Errorf("%w (must be between 0 and 1): %s: %v", apperror.ErrInvalidWarningThreshold, s.Name, s.WarningThreshold)
+	}
+
+	if err := s.validatePulsePolicy(); err != nil {
+		return err
+	}
+	return s.validateMetricRules()
+}
+
+// notificationTargets resolves where problems with the service are sent, in order of precedence: the notifiers
+// of the service itself, the first route matching one of its tags and finally the defaults.
+func (s *Service) notificationTargets(routes []Route, defaults notification.ProtocolTargets) notification.ProtocolTargets {
+	primary := s.Notifiers
+	if len(primary) == 0 {
+		for _, route := range routes {
+			if s.hasAnyTag(route.Tags) {
+				primary = route.Notifiers
+				break
+			}
+		}
+	}
+	if len(primary) == 0 {
+		return defaults
+	}
+
+	return notification.ProtocolTargets{
+		Primary: primary,
+		Fallback: slices.DeleteFunc(slices.Clone(defaults.Fallback), func(protocol string) bool {
+			return slices.Contains(primary, protocol)
+		}),
+	}
+}
+
+func (s *Service) hasAnyTag(tags []string) bool {
+	return slices.ContainsFunc(tags, func(tag string) bool {
+		return slices.Contains(s.Tags, tag)
+	})
+}
+
+// openIncident marks the start of an incident and reports whether one wasn't already open.
+func (s *Service) openIncident(at time.Time, flapping FlappingSettings) bool {
+	if !s.IncidentStart.IsZero() {
+		return false
+	}
+
+	s.IncidentStart = at
+	s.recordTransition(at, flapping)
+	return true
+}
+
+// closeIncident marks the end of the ongoing incident and reports whether there was one.
+func (s *Service) closeIncident(at time.Time, flapping FlappingSettings) bool {
+	if s.IncidentStart.IsZero() {
+		return false
+	}
+
+	s.addIncident(Incident{Start: s.IncidentStart, End: at})
+	s.IncidentStart = time.Time{}
+	s.recordTransition(at, flapping)
+	return true
+}
+
+// downSince returns when the service is down according to its pulse policies, unless it pulses before then.
+func (s *Service) downSince() time.Time {
+	deadline := s.LastPulse.Add(s.timeout())
+	if s.MinPulses > 0 {
+		if minPulses := s.minPulsesDeadline(); minPulses.Before(deadline) {
+			deadline = minPulses
+		}
+	}
+	for _, since := range []time.Time{s.metricViolationSince, s.checkFailureSince()} {
+		if !since.IsZero() && since.Before(deadline) {
+			deadline = since
+		}
+	}
+	return deadline
+}
+
+// applyConfig takes over the configuration of other, keeping the runtime state of the service. A changed min pulses
+// policy starts with a fresh pulse history, pulses are only recorded while the policy is enabled.
+func (s *Service) applyConfig(other *Service) {
+	if s.MinPulses != other.MinPulses || s.MinPulsesWindow != other.MinPulsesWindow {
+		s.resetPulseHistory(s.now())
+	}
+	s.HeartbeatTimeoutDuration = other.HeartbeatTimeoutDuration
+	s.Notifiers = other.Notifiers
+	s.Tags = other.Tags
+	s.Group = other.Group
+	s.DependsOn = other.DependsOn
+	s.WarningThreshold = other.WarningThreshold
+	s.WarningNotifiers = other.WarningNotifiers
+	s.MissedIntervals = other.MissedIntervals
+	s.MinPulses = other.MinPulses
+	s.MinPulsesWindow = other.MinPulsesWindow
+	s.MetricRules = other.MetricRules
+}
+
+// now returns the current time according to the clock of the service.
+func (s *Service) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
 func (s *Service) isProblematic() bool {
-	return time.Since(s.LastPulse) >= s.HeartbeatTimeoutDuration
+	if s.Pause != nil {
+		return false
+	}
+	return !s.now().Before(s.downSince())
+}
+
+// problemReasons explains why the service is problematic beyond a missing pulse.
+func (s *Service) problemReasons() []string {
+	reasons := slices.Clone(s.metricViolations)
+	for _, id := range s.checkIDs() {
+		if failure := s.checks[id].failure; len(failure) != 0 {
+			reasons = append(reasons, fmt.Sprintf("%s check failed: %s", id, failure))
+		}
+	}
+	return reasons
 }
 
 func (s *Service) isProblematicReportCooldownActive(cooldownDuration time.Duration) bool {
-	return time.Since(s.LastProblemReported) < cooldownDuration
+	return s.now().Sub(s.LastProblemReported) < cooldownDuration
 }
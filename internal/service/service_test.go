@@ -1,8 +1,30 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/internal/app/timings"
+	"service-uptime-center/internal/checker"
+	"service-uptime-center/internal/clock"
+	"service-uptime-center/notification"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"gopkg.in/yaml.v3"
 )
 
 func TestNewManagerDuplicateServiceNames(t *testing.T) {
@@ -49,9 +71,1783 @@ func TestGetProblematicServicesTimeoutEdgeCases(t *testing.T) {
 	manager.cfg.Services[0].LastPulse = now.Add(-time.Second)
 	manager.cfg.Services[1].LastPulse = now.Add(-time.Second + time.Millisecond)
 
-	problematic := manager.getProblematicServices()
+	problematic := manager.getDueProblematicServices(time.Hour)
 
 	if len(problematic) != 1 || problematic[0].Name != "justExpired" {
 		t.Error("should detect exactly expired service but not almost-expired")
 	}
 }
+
+func TestAcknowledge(t *testing.T) {
+	cfg := Config{
+		Services: []Service{
+			{Name: "healthy", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "down", HeartbeatTimeoutDuration: time.Minute},
+		},
+	}
+	manager, _ := NewManager(&cfg)
+	manager.cfg.Services[1].LastPulse = time.Now().Add(-time.Hour)
+
+	if _, err := manager.Acknowledge("nonexistent", "", 0); !errors.Is(err, apperror.ErrServiceNotFound) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+	if _, err := manager.Acknowledge("healthy", "", 0); !errors.Is(err, apperror.ErrServiceNotProblematic) {
+		t.Errorf("expected ErrServiceNotProblematic, got %v", err)
+	}
+	if _, err := manager.Acknowledge("down", "", -time.Minute); !errors.Is(err, apperror.ErrInvalidAckExpiry) {
+		t.Errorf("expected ErrInvalidAckExpiry, got %v", err)
+	}
+
+	ack, err := manager.Acknowledge("down", "looking into it", time.Hour)
+	if err != nil {
+		t.Fatalf("expected acknowledge to succeed, got %v", err)
+	}
+	if ack.Comment != "looking into it" || ack.ExpiresAt.IsZero() {
+		t.Errorf("unexpected acknowledgement: %+v", ack)
+	}
+	if !manager.cfg.Services[1].Acknowledgement.isActive(time.Now()) {
+		t.Error("acknowledgement should be active right after creation")
+	}
+	if ack.isActive(ack.ExpiresAt) {
+		t.Error("acknowledgement should not be active once it has expired")
+	}
+
+	manager.UpdatePulse("down")
+	if manager.cfg.Services[1].Acknowledgement != nil {
+		t.Error("acknowledgement should be cleared when the service recovers")
+	}
+}
+
+func TestSilencesPersistAcrossRestarts(t *testing.T) {
+	newConfig := func(stateDir string) *Config {
+		return &Config{
+			Services: []Service{
+				{Name: "api", HeartbeatTimeoutDuration: time.Minute},
+				{Name: "db", HeartbeatTimeoutDuration: time.Minute},
+			},
+			StateDirectory: stateDir,
+		}
+	}
+
+	stateDir := t.TempDir()
+	manager, err := NewManager(newConfig(stateDir))
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	if _, err := manager.CreateSilence(Silence{Services: []string{"missing"}}, time.Hour); !errors.Is(err, apperror.ErrServiceNotFound) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+	if _, err := manager.CreateSilence(Silence{Services: []string{"api"}}, 0); !errors.Is(err, apperror.ErrInvalidSilence) {
+		t.Errorf("expected ErrInvalidSilence without an end, got %v", err)
+	}
+
+	silence, err := manager.CreateSilence(Silence{Services: []string{"api"}, Comment: "deploy"}, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create silence: %v", err)
+	}
+	if silence == manager.silences[len(manager.silences)-1] {
+		t.Error("expected a copy of the created silence, the stored one may change while it's encoded")
+	}
+
+	now := time.Now()
+	if _, silenced := manager.silencedBy(manager.lookup["api"], now); !silenced {
+		t.Error("api should be silenced")
+	}
+	if _, silenced := manager.silencedBy(manager.lookup["db"], now); silenced {
+		t.Error("db should not be silenced")
+	}
+
+	restarted, err := NewManager(newConfig(stateDir))
+	if err != nil {
+		t.Fatalf("failed to recreate manager: %v", err)
+	}
+	silences := restarted.ListSilences()
+	if len(silences) != 1 || silences[0].ID != silence.ID || silences[0].Comment != "deploy" {
+		t.Fatalf("expected silence to be restored, got %+v", silences)
+	}
+
+	expired, err := restarted.ExpireSilence(silence.ID)
+	if err != nil {
+		t.Fatalf("failed to expire silence: %v", err)
+	}
+	if expired.state(time.Now()) != SilenceStateExpired {
+		t.Errorf("expected silence to be expired, got %s", expired.state(time.Now()))
+	}
+	if _, silenced := restarted.silencedBy(restarted.lookup["api"], time.Now()); silenced {
+		t.Error("api should not be silenced after the silence expired")
+	}
+
+	if err := restarted.DeleteSilence(silence.ID); err != nil {
+		t.Fatalf("failed to delete silence: %v", err)
+	}
+	if err := restarted.DeleteSilence(silence.ID); !errors.Is(err, apperror.ErrSilenceNotFound) {
+		t.Errorf("expected ErrSilenceNotFound, got %v", err)
+	}
+}
+
+func TestMaintenanceWindows(t *testing.T) {
+	now := time.Now()
+	cfg := Config{
+		Services: []Service{
+			{Name: "rsync", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "restic", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "web", HeartbeatTimeoutDuration: time.Minute},
+		},
+		MaintenanceWindows: []MaintenanceWindow{
+			{Name: "always", Services: []string{"rsync"}, Schedule: "* * * * *", Duration: 2 * time.Minute},
+			{Name: "migration", Services: []string{"restic"}, Start: now.Add(-time.Hour), End: now.Add(time.Hour)},
+			{Name: "past", Services: []string{"web"}, Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	manager, _ := NewManager(&cfg)
+
+	for name, expected := range map[string]bool{"rsync": true, "restic": true, "web": false} {
+		if _, silenced := manager.silencedBy(manager.lookup[name], now); silenced != expected {
+			t.Errorf("expected %s silenced to be %t", name, expected)
+		}
+	}
+
+	for _, window := range []MaintenanceWindow{
+		{Name: "no-services", Schedule: "* * * * *", Duration: time.Minute},
+		{Name: "unknown", Services: []string{"nope"}, Schedule: "* * * * *", Duration: time.Minute},
+		{Name: "no-duration", Services: []string{"web"}, Schedule: "* * * * *"},
+		{Name: "bad-cron", Services: []string{"web"}, Schedule: "* * *", Duration: time.Minute},
+		{Name: "both", Services: []string{"web"}, Schedule: "* * * * *", Duration: time.Minute, Start: now, End: now.Add(time.Hour)},
+		{Name: "inverted", Services: []string{"web"}, Start: now, End: now.Add(-time.Hour)},
+		{Name: "empty", Services: []string{"web"}},
+	} {
+		invalid := Config{Services: cfg.Services, MaintenanceWindows: []MaintenanceWindow{window}}
+		if err := invalid.Validate(); !errors.Is(err, apperror.ErrInvalidMaintenanceWindow) {
+			t.Errorf("expected ErrInvalidMaintenanceWindow for %s, got %v", window.Name, err)
+		}
+	}
+}
+
+func TestRuntimeStateNotInConfig(t *testing.T) {
+	data := `
+services:
+  - name: backup
+    heartbeat_timeout_duration: 1h
+    lastpulse: 2026-10-01T12:00:00Z
+    pause:
+      comment: forged
+    acknowledgement:
+      comment: forged
+    incidentstart: 2026-10-01T12:00:00Z
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	service := cfg.Services[0]
+	if !service.LastPulse.IsZero() || service.Pause != nil || service.Acknowledgement != nil || !service.IncidentStart.IsZero() {
+		t.Errorf("expected the runtime state not to be read from the config, got %+v", service)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	cfg := Config{
+		Services: []Service{
+			{Name: "manual", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "auto", HeartbeatTimeoutDuration: time.Minute},
+		},
+	}
+	manager, _ := NewManager(&cfg)
+	longAgo := time.Now().Add(-time.Hour)
+	manager.cfg.Services[0].LastPulse = longAgo
+	manager.cfg.Services[1].LastPulse = longAgo
+
+	if err := manager.Resume("manual"); !errors.Is(err, apperror.ErrServiceNotPaused) {
+		t.Errorf("expected ErrServiceNotPaused, got %v", err)
+	}
+	if _, err := manager.Pause("nonexistent", "", false); !errors.Is(err, apperror.ErrServiceNotFound) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+
+	manual := manager.lookup["manual"]
+	manager.openIncident(manual, longAgo.Add(time.Minute))
+	if _, err := manager.Pause("manual", "decommissioning", false); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if !manual.IncidentStart.IsZero() || len(manual.incidents) != 1 || !manual.incidents[0].End.Equal(manual.Pause.CreatedAt) {
+		t.Errorf("expected pausing to end the ongoing incident, got %+v", manual.incidents)
+	}
+	if _, err := manager.Pause("auto", "", true); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if problematic := manager.getDueProblematicServices(time.Hour); len(problematic) != 0 {
+		t.Errorf("paused services should not be problematic, got %v", problematic)
+	}
+
+	manager.UpdatePulse("manual")
+	manager.UpdatePulse("auto")
+	if manager.lookup["manual"].Pause == nil {
+		t.Error("a pulse should not resume a service paused without auto resume")
+	}
+	if manager.lookup["auto"].Pause != nil {
+		t.Error("a pulse should resume a service paused with auto resume")
+	}
+
+	manager.lookup["manual"].LastPulse = longAgo
+	if err := manager.Resume("manual"); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+	if manager.lookup["manual"].isProblematic() {
+		t.Error("a resumed service should get a fresh heartbeat timeout")
+	}
+	if len(manual.incidents) != 1 {
+		t.Errorf("expected the paused time not to count as an incident, got %+v", manual.incidents)
+	}
+}
+
+func TestAutoResume(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	manager, _ := NewManagerWithClock(&Config{
+		Services: []Service{{Name: "backup", HeartbeatTimeoutDuration: 2 * time.Hour, MinPulses: 3, MinPulsesWindow: time.Hour}},
+	}, fake)
+	service := manager.lookup["backup"]
+
+	manager.ReportCheckResult("backup", "file", false, false, "backup is 26h old", nil)
+	if _, err := manager.Pause("backup", "moving the repository", true); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+
+	fake.Advance(90 * time.Minute)
+	manager.UpdatePulse("backup")
+	if service.Pause != nil {
+		t.Fatal("expected a pulse to resume the service")
+	}
+	if service.isProblematic() || !service.IncidentStart.IsZero() {
+		t.Errorf("expected the service to start over when resumed by a pulse, got reasons %v", service.problemReasons())
+	}
+
+	events, _, _ := manager.Events("backup", EventQuery{})
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	if diff := cmp.Diff([]EventType{EventPulse, EventResumed}, types[:2]); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAcknowledgementEndsWithPause(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	manager, _ := NewManagerWithClock(&Config{
+		Services: []Service{{Name: "db", HeartbeatTimeoutDuration: 10 * time.Minute}},
+	}, fake)
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	instr := MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: 6 * time.Hour},
+		Notifiers: targets,
+	}
+
+	fake.Advance(10 * time.Minute)
+	if _, err := manager.Acknowledge("db", "migrating", 0); err != nil {
+		t.Fatalf("failed to acknowledge: %v", err)
+	}
+	if _, err := manager.Pause("db", "", false); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if err := manager.Resume("db"); err != nil {
+		t.Fatalf("failed to resume: %v", err)
+	}
+
+	fake.Advance(10 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if notifications := sent(); len(notifications) != 1 || !strings.Contains(notifications[0].body, "db") {
+		t.Errorf("expected the acknowledgement to end with the pause and db to be reported, got %+v", notifications)
+	}
+}
+
+func TestDynamicServices(t *testing.T) {
+	newConfig := func(stateDir string) *Config {
+		return &Config{
+			Services:       []Service{{Name: "static", HeartbeatTimeoutDuration: time.Minute}},
+			StateDirectory: stateDir,
+		}
+	}
+
+	stateDir := t.TempDir()
+	manager, _ := NewManager(newConfig(stateDir))
+
+	for _, test := range []struct {
+		def         ServiceDefinition
+		expectError error
+	}{
+		{ServiceDefinition{Name: "static", HeartbeatTimeoutDuration: "1h"}, apperror.ErrDuplicateServiceNames},
+		{ServiceDefinition{Name: "x", HeartbeatTimeoutDuration: "1h"}, apperror.ErrInvalidServiceName},
+		{ServiceDefinition{Name: "short", HeartbeatTimeoutDuration: "1s"}, apperror.ErrHeartbeatTimeoutTooShort},
+		{ServiceDefinition{Name: "garbage", HeartbeatTimeoutDuration: "soon"}, apperror.ErrInvalidServiceDefinition},
+	} {
+		if _, err := manager.CreateService(test.def); !errors.Is(err, test.expectError) {
+			t.Errorf("expected %v when creating %+v, got %v", test.expectError, test.def, err)
+		}
+	}
+
+	if _, err := manager.CreateService(ServiceDefinition{Name: "ci-job", HeartbeatTimeoutDuration: "1h"}); err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	if !manager.UpdatePulse("ci-job") {
+		t.Error("dynamic service should accept pulses")
+	}
+
+	if _, err := manager.UpdateService("static", ServiceDefinition{HeartbeatTimeoutDuration: "2h"}); !errors.Is(err, apperror.ErrStaticService) {
+		t.Errorf("expected ErrStaticService, got %v", err)
+	}
+	if _, err := manager.UpdateService("ci-job", ServiceDefinition{Name: "renamed", HeartbeatTimeoutDuration: "2h"}); !errors.Is(err, apperror.ErrInvalidServiceDefinition) {
+		t.Errorf("expected ErrInvalidServiceDefinition when renaming, got %v", err)
+	}
+	updated, err := manager.UpdateService("ci-job", ServiceDefinition{HeartbeatTimeoutDuration: "2h"})
+	if err != nil {
+		t.Fatalf("failed to update service: %v", err)
+	}
+	if updated.HeartbeatTimeoutDuration != "2h0m0s" {
+		t.Errorf("expected updated timeout, got %v", updated.HeartbeatTimeoutDuration)
+	}
+
+	restarted, _ := NewManager(newConfig(stateDir))
+	restored, exists := restarted.lookup["ci-job"]
+	if !exists || !restored.Dynamic || restored.HeartbeatTimeoutDuration != 2*time.Hour {
+		t.Fatalf("expected dynamic service to be restored, got %+v", restored)
+	}
+
+	if err := restarted.DeleteService("static"); !errors.Is(err, apperror.ErrStaticService) {
+		t.Errorf("expected ErrStaticService, got %v", err)
+	}
+	if err := restarted.DeleteService("ci-job"); err != nil {
+		t.Fatalf("failed to delete service: %v", err)
+	}
+	if restarted.UpdatePulse("ci-job") {
+		t.Error("deleted service should not accept pulses")
+	}
+
+	restartedAgain, _ := NewManager(newConfig(stateDir))
+	if _, exists := restartedAgain.lookup["ci-job"]; exists {
+		t.Error("deleted service should not be restored")
+	}
+}
+
+func TestAutoProvisioning(t *testing.T) {
+	cfg := Config{
+		Services: []Service{{Name: "static", HeartbeatTimeoutDuration: time.Minute}},
+		AutoProvision: []ProvisionTemplate{
+			{Pattern: "ci-*", HeartbeatTimeoutDuration: time.Hour, Notifiers: []string{"ntfy"}},
+			{Pattern: "*", HeartbeatTimeoutDuration: 2 * time.Hour},
+		},
+		StateDirectory: t.TempDir(),
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	manager, _ := NewManager(&cfg)
+
+	if !manager.UpdatePulse("ci-runner-42") {
+		t.Fatal("pulse from a service matching a template should be accepted")
+	}
+	provisioned := manager.lookup["ci-runner-42"]
+	if provisioned == nil || !provisioned.Dynamic || provisioned.HeartbeatTimeoutDuration != time.Hour {
+		t.Fatalf("expected service to be provisioned from the first matching template, got %+v", provisioned)
+	}
+	if targets := provisioned.notificationTargets(nil, notification.ProtocolTargets{Primary: []string{"mail"}, Fallback: []string{"ntfy"}}); len(targets.Primary) != 1 || targets.Primary[0] != "ntfy" || len(targets.Fallback) != 0 {
+		t.Errorf("expected template notifiers to override primary and be removed from fallback, got %+v", targets)
+	}
+	if len(provisioned.incidents) != 0 || !provisioned.IncidentStart.IsZero() {
+		t.Errorf("expected a provisioned service to start without incidents, got %+v", provisioned.incidents)
+	}
+	events, _, _ := manager.Events("ci-runner-42", EventQuery{})
+	for _, event := range events {
+		if event.Type == EventIncidentOpened || event.Type == EventIncidentResolved {
+			t.Errorf("expected no incident events for a provisioned service, got %+v", event)
+		}
+	}
+
+	if manager.UpdatePulse("x") {
+		t.Error("pulse with an invalid name should not provision a service")
+	}
+
+	restarted, _ := NewManager(&Config{Services: cfg.Services, StateDirectory: cfg.StateDirectory})
+	if _, exists := restarted.lookup["ci-runner-42"]; !exists {
+		t.Error("auto provisioned services should be persisted")
+	}
+
+	if err := (&Config{
+		Services:      cfg.Services,
+		AutoProvision: []ProvisionTemplate{{Pattern: "[", HeartbeatTimeoutDuration: time.Hour}},
+	}).Validate(); !errors.Is(err, apperror.ErrInvalidProvisionTemplate) {
+		t.Errorf("expected ErrInvalidProvisionTemplate for a bad pattern, got %v", err)
+	}
+	if err := (&Config{
+		Services:      cfg.Services,
+		AutoProvision: []ProvisionTemplate{{Pattern: "ci-*", HeartbeatTimeoutDuration: time.Second}},
+	}).Validate(); !errors.Is(err, apperror.ErrHeartbeatTimeoutTooShort) {
+		t.Errorf("expected ErrHeartbeatTimeoutTooShort for a short template timeout, got %v", err)
+	}
+	if err := (&Config{
+		Services:      cfg.Services,
+		AutoProvision: []ProvisionTemplate{{Pattern: "*", HeartbeatTimeoutDuration: time.Hour, WarningThreshold: 2}},
+	}).Validate(); !errors.Is(err, apperror.ErrInvalidWarningThreshold) {
+		t.Errorf("expected ErrInvalidWarningThreshold for a catch-all template, got %v", err)
+	}
+}
+
+func TestTagsAndGroups(t *testing.T) {
+	cfg := Config{
+		Services: []Service{
+			{Name: "restic", HeartbeatTimeoutDuration: time.Minute, Tags: []string{"backup"}, Group: "nas"},
+			{Name: "rsync", HeartbeatTimeoutDuration: time.Minute, Tags: []string{"backup"}, Group: "nas", Notifiers: []string{"mail"}},
+			{Name: "web", HeartbeatTimeoutDuration: time.Minute},
+		},
+		Routes: []Route{{Tags: []string{"backup"}, Notifiers: []string{"ntfy"}}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	manager, _ := NewManager(&cfg)
+
+	defaults := notification.ProtocolTargets{Primary: []string{"mail"}}
+	for name, expected := range map[string]string{"restic": "ntfy", "rsync": "mail", "web": "mail"} {
+		if targets := manager.lookup[name].notificationTargets(cfg.Routes, defaults); targets.Primary[0] != expected {
+			t.Errorf("expected %s to notify %s, got %v", name, expected, targets.Primary)
+		}
+	}
+
+	if _, err := manager.CreateSilence(Silence{Tags: []string{"backup"}}, time.Hour); err != nil {
+		t.Fatalf("failed to create silence: %v", err)
+	}
+	for name, expected := range map[string]bool{"restic": true, "rsync": true, "web": false} {
+		if _, silenced := manager.silencedBy(manager.lookup[name], time.Now()); silenced != expected {
+			t.Errorf("expected %s silenced to be %t", name, expected)
+		}
+	}
+
+	status, err := manager.GetStatusJSON(StatusFilter{Tag: "backup"})
+	if err != nil {
+		t.Fatalf("failed to get status: %v", err)
+	}
+	var parsed struct {
+		Services []struct {
+			Name string `json:"name"`
+		} `json:"services"`
+	}
+	if err := json.Unmarshal(status, &parsed); err != nil {
+		t.Fatalf("failed to parse status: %v", err)
+	}
+	if len(parsed.Services) != 2 || parsed.Services[0].Name != "restic" || parsed.Services[1].Name != "rsync" {
+		t.Errorf("expected only backup services in status, got %+v", parsed.Services)
+	}
+
+	report := newProblemReport(defaults)
+	for _, service := range manager.services {
+		report.add(service, time.Hour, time.Minute)
+	}
+	if title := report.title(); title != "Problem detected with 3 services (nas)" {
+		t.Errorf("unexpected report title: %q", title)
+	}
+	body := report.body()
+	if !strings.Contains(body, "nas (2):") || !strings.Contains(body, "ungrouped (1):") {
+		t.Errorf("expected report body to be summarized by group, got %q", body)
+	}
+
+	if err := (&Config{Services: cfg.Services, Routes: []Route{{Tags: []string{"backup"}}}}).Validate(); !errors.Is(err, apperror.ErrInvalidRoute) {
+		t.Errorf("expected ErrInvalidRoute, got %v", err)
+	}
+}
+
+type sentNotification struct {
+	title string
+	body  string
+}
+
+// newNtfyRecorder returns a notification manager whose ntfy protocol posts to a local server recording every notification.
+func newNtfyRecorder(t *testing.T) (*notification.Manager, notification.ProtocolTargets, func() []sentNotification) {
+	var mutex sync.Mutex
+	var sent []sentNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mutex.Lock()
+		defer mutex.Unlock()
+		sent = append(sent, sentNotification{title: r.Header.Get("Title"), body: string(body)})
+	}))
+	t.Cleanup(server.Close)
+
+	manager := notification.NewManager(&notification.ManagerConfig{
+		Ntfy: notification.NtfyConfig{Server: server.URL, Topic: "alerts"},
+	})
+	return manager, notification.ProtocolTargets{Primary: []string{"ntfy"}}, func() []sentNotification {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return slices.Clone(sent)
+	}
+}
+
+func TestDependenciesSuppressCascadingAlerts(t *testing.T) {
+	cfg := Config{
+		Services: []Service{
+			{Name: "nas", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "rsync", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"nas"}},
+			{Name: "restic", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"nas"}},
+			{Name: "report", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"rsync"}},
+			{Name: "web", HeartbeatTimeoutDuration: time.Minute},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	manager, _ := NewManager(&cfg)
+	for _, service := range manager.services {
+		if service.Name != "web" {
+			service.LastPulse = time.Now().Add(-time.Hour)
+		}
+	}
+
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	manager.handleProblematicServices(notificationManager, targets, manager.getDueProblematicServices(time.Hour), time.Hour)
+
+	notifications := sent()
+	if len(notifications) != 1 {
+		t.Fatalf("expected a single notification, got %d", len(notifications))
+	}
+	if notifications[0].title != "Problem detected with 1 services" {
+		t.Errorf("expected only the root cause to be counted, got %q", notifications[0].title)
+	}
+	if !strings.Contains(notifications[0].body, "Likely caused by nas: report, restic, rsync") {
+		t.Errorf("expected dependents to be listed next to the root cause, got %q", notifications[0].body)
+	}
+
+	for _, test := range []struct {
+		services    []Service
+		expectError error
+	}{
+		{
+			[]Service{{Name: "aa", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"missing"}}},
+			apperror.ErrUnknownDependency,
+		},
+		{
+			[]Service{
+				{Name: "aa", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"bb"}},
+				{Name: "bb", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"cc"}},
+				{Name: "cc", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"aa"}},
+			},
+			apperror.ErrDependencyCycle,
+		},
+		{
+			[]Service{{Name: "aa", HeartbeatTimeoutDuration: time.Minute, DependsOn: []string{"aa"}}},
+			apperror.ErrDependencyCycle,
+		},
+	} {
+		if err := (&Config{Services: test.services}).Validate(); !errors.Is(err, test.expectError) {
+			t.Errorf("expected %v, got %v", test.expectError, err)
+		}
+	}
+
+	if _, err := manager.CreateService(ServiceDefinition{Name: "dyn", HeartbeatTimeoutDuration: "1h", DependsOn: []string{"missing"}}); !errors.Is(err, apperror.ErrUnknownDependency) {
+		t.Errorf("expected ErrUnknownDependency, got %v", err)
+	}
+	if _, err := manager.CreateService(ServiceDefinition{Name: "dyn", HeartbeatTimeoutDuration: "1h", DependsOn: []string{"nas"}}); err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	if _, err := manager.CreateService(ServiceDefinition{Name: "dyn-child", HeartbeatTimeoutDuration: "1h", DependsOn: []string{"dyn"}}); err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	if err := manager.DeleteService("dyn"); !errors.Is(err, apperror.ErrServiceHasDependents) {
+		t.Errorf("expected ErrServiceHasDependents, got %v", err)
+	}
+	if _, err := manager.UpdateService("dyn", ServiceDefinition{HeartbeatTimeoutDuration: "1h", DependsOn: []string{"dyn-child"}}); !errors.Is(err, apperror.ErrDependencyCycle) {
+		t.Errorf("expected ErrDependencyCycle, got %v", err)
+	}
+}
+
+func TestDependentsWithLongerTimeouts(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	manager, _ := NewManagerWithClock(&Config{
+		Services: []Service{
+			{Name: "nas", HeartbeatTimeoutDuration: 10 * time.Minute},
+			{Name: "rsync", HeartbeatTimeoutDuration: 15 * time.Minute, DependsOn: []string{"nas"}},
+		},
+	}, fake)
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	instr := MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: time.Hour},
+		Notifiers: targets,
+	}
+
+	fake.Advance(10 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	fake.Advance(5 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if notifications := sent(); len(notifications) != 1 || strings.Contains(notifications[0].body, "rsync") {
+		t.Fatalf("expected only nas to be reported before rsync went down, got %+v", notifications)
+	}
+
+	// rsync went down between the reports of nas, it's listed with the next one.
+	fake.Set(start.Add(70 * time.Minute))
+	manager.evaluate(notificationManager, instr)
+	notifications := sent()
+	if len(notifications) != 2 || !strings.Contains(notifications[1].body, "Likely caused by nas: rsync") {
+		t.Fatalf("expected rsync to be listed as caused by nas, got %+v", notifications)
+	}
+	if rsync := manager.lookup["rsync"]; !rsync.IncidentStart.Equal(start.Add(15 * time.Minute)) {
+		t.Errorf("expected the incident of rsync to start when it went down, got %v", rsync.IncidentStart)
+	}
+}
+
+func TestPulseBeforeReport(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	manager, _ := NewManagerWithClock(&Config{Services: []Service{{Name: "web", HeartbeatTimeoutDuration: 10 * time.Minute}}}, fake)
+	notificationManager, targets, sent := newNtfyRecorder(t)
+
+	fake.Advance(10 * time.Minute)
+	due := manager.getDueProblematicServices(time.Hour)
+	manager.UpdatePulse("web")
+	manager.handleProblematicServices(notificationManager, targets, due, 0)
+	if notifications := sent(); len(notifications) != 0 {
+		t.Errorf("expected a service that pulsed in the meantime not to be reported, got %+v", notifications)
+	}
+	if web := manager.lookup["web"]; !web.IncidentStart.IsZero() {
+		t.Errorf("expected no incident to be opened, got one starting at %v", web.IncidentStart)
+	}
+}
+
+func TestFlappingDetection(t *testing.T) {
+	cfg := Config{
+		Services: []Service{
+			{Name: "flaky", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "stable", HeartbeatTimeoutDuration: time.Minute},
+		},
+		Flapping: FlappingSettings{Window: time.Hour, Threshold: 4},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected config to be valid, got %v", err)
+	}
+	manager, _ := NewManager(&cfg)
+	flaky := manager.lookup["flaky"]
+
+	for range 2 {
+		flaky.LastPulse = time.Now().Add(-2 * time.Minute)
+		manager.UpdatePulse("flaky")
+	}
+	if !flaky.isFlapping() {
+		t.Fatalf("expected service to be flapping after %d transitions", len(flaky.transitions))
+	}
+	if manager.lookup["stable"].isFlapping() {
+		t.Error("stable service should not be flapping")
+	}
+
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	manager.handleFlappingServices(notificationManager, targets)
+	manager.handleFlappingServices(notificationManager, targets)
+	if notifications := sent(); len(notifications) != 1 || !strings.Contains(notifications[0].body, "flaky") {
+		t.Fatalf("expected a single flapping notification, got %+v", notifications)
+	}
+
+	flaky.LastPulse = time.Now().Add(-2 * time.Minute)
+	manager.handleProblematicServices(notificationManager, targets, manager.getDueProblematicServices(time.Hour), time.Hour)
+	if notifications := sent(); len(notifications) != 1 {
+		t.Errorf("expected problem reports to be suppressed while flapping, got %+v", notifications)
+	}
+
+	flaky.updateFlapping(time.Now().Add(2*time.Hour), cfg.Flapping)
+	if flaky.isFlapping() {
+		t.Error("expected service to stop flapping once the transitions left the window")
+	}
+
+	for _, settings := range []FlappingSettings{
+		{Window: time.Hour, Threshold: 1},
+		{Window: 0, Threshold: 3},
+	} {
+		if err := (&Config{Services: cfg.Services, Flapping: settings}).Validate(); !errors.Is(err, apperror.ErrInvalidFlappingSettings) {
+			t.Errorf("expected ErrInvalidFlappingSettings for %+v, got %v", settings, err)
+		}
+	}
+}
+
+func TestEventHistory(t *testing.T) {
+	history := newEventHistory(3)
+	base := time.Now()
+	for i := range 5 {
+		history.add(Event{Time: base.Add(time.Duration(i) * time.Minute), Message: fmt.Sprint(i)})
+	}
+
+	var messages []string
+	for _, event := range history.all() {
+		messages = append(messages, event.Message)
+	}
+	if diff := cmp.Diff([]string{"2", "3", "4"}, messages); diff != "" {
+		t.Errorf("ring buffer mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEventsQueryAndPersistence(t *testing.T) {
+	newConfig := func(stateDir string) *Config {
+		return &Config{
+			Services:       []Service{{Name: "api", HeartbeatTimeoutDuration: time.Minute}},
+			StateDirectory: stateDir,
+			Events:         EventSettings{HistorySize: 10, Persist: true},
+		}
+	}
+
+	stateDir := t.TempDir()
+	manager, err := NewManager(newConfig(stateDir))
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	manager.lookup["api"].LastPulse = time.Now().Add(-time.Hour)
+	manager.UpdatePulse("api")
+	for range 3 {
+		manager.UpdatePulse("api")
+	}
+
+	events, total, err := manager.Events("api", EventQuery{})
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	expected := []EventType{EventPulse, EventPulse, EventPulse, EventPulse, EventIncidentResolved, EventIncidentOpened}
+	if diff := cmp.Diff(expected, types); diff != "" || total != len(expected) {
+		t.Errorf("events mismatch, total %d (-want +got):\n%s", total, diff)
+	}
+
+	page, total, _ := manager.Events("api", EventQuery{Limit: 2, Offset: 3})
+	if len(page) != 2 || total != len(expected) || page[0].Type != EventPulse || page[1].Type != EventIncidentResolved {
+		t.Errorf("unexpected page: %+v (total %d)", page, total)
+	}
+
+	incidentOnly, _, _ := manager.Events("api", EventQuery{Until: events[len(events)-1].Time})
+	if len(incidentOnly) != 1 || incidentOnly[0].Type != EventIncidentOpened {
+		t.Errorf("expected time range to only include the incident, got %+v", incidentOnly)
+	}
+
+	if _, _, err := manager.Events("missing", EventQuery{}); !errors.Is(err, apperror.ErrServiceNotFound) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+
+	restarted, err := NewManager(newConfig(stateDir))
+	if err != nil {
+		t.Fatalf("failed to recreate manager: %v", err)
+	}
+	restored, _, _ := restarted.Events("api", EventQuery{})
+	if diff := cmp.Diff(events, restored, cmpopts.EquateApproxTime(0)); diff != "" {
+		t.Errorf("restored events mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := (&Config{Services: newConfig("").Services, Events: EventSettings{Persist: true}}).Validate(); !errors.Is(err, apperror.ErrInvalidEventSettings) {
+		t.Errorf("expected ErrInvalidEventSettings without a state directory, got %v", err)
+	}
+
+	for range 100 {
+		restarted.UpdatePulse("api")
+	}
+	data, err := os.ReadFile(filepath.Join(stateDir, "events.log"))
+	if err != nil {
+		t.Fatalf("failed to read event log: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines > 20 {
+		t.Errorf("expected the event log to be compacted to the history size, got %d events", lines)
+	}
+}
+
+func TestUptimeStats(t *testing.T) {
+	now := time.Now()
+	service := &Service{
+		Name:                     "api",
+		HeartbeatTimeoutDuration: time.Minute,
+		LastPulse:                now,
+		MonitoredSince:           now.Add(-48 * time.Hour),
+	}
+	// One incident outside of the 24h window, one inside and one straddling its start.
+	service.addIncident(Incident{Start: now.Add(-40 * time.Hour), End: now.Add(-39 * time.Hour)})
+	service.addIncident(Incident{Start: now.Add(-24*time.Hour - 30*time.Minute), End: now.Add(-24*time.Hour + 30*time.Minute)})
+	service.addIncident(Incident{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)})
+
+	day := service.uptime(now, 24*time.Hour)
+	expected := UptimeStats{
+		UptimePercent:      100 * (1 - 1.5/24),
+		Incidents:          2,
+		Downtime:           "1h30m0s",
+		MeanTimeToRecovery: "1h0m0s",
+	}
+	if diff := cmp.Diff(expected, day, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("24h uptime mismatch (-want +got):\n%s", diff)
+	}
+
+	// The 7d window only covers the 48h the service has been monitored.
+	week := service.uptime(now, 7*24*time.Hour)
+	if week.Incidents != 3 || week.Downtime != "3h0m0s" || week.UptimePercent != 100*(1-3.0/48) {
+		t.Errorf("unexpected 7d uptime: %+v", week)
+	}
+
+	service.LastPulse = now.Add(-time.Hour)
+	ongoing := service.uptime(now, 24*time.Hour)
+	if ongoing.Incidents != 3 || ongoing.Downtime != "2h29m0s" || ongoing.MeanTimeToRecovery != "1h0m0s" {
+		t.Errorf("expected the ongoing incident to count as downtime without affecting MTTR, got %+v", ongoing)
+	}
+
+	service.addIncident(Incident{Start: now.Add(100 * 24 * time.Hour), End: now.Add(100*24*time.Hour + time.Minute)})
+	if len(service.incidents) != 1 {
+		t.Errorf("expected incidents older than the retention to be dropped, got %d", len(service.incidents))
+	}
+}
+
+func TestUptimePersistsAcrossRestarts(t *testing.T) {
+	newConfig := func(stateDir string) *Config {
+		return &Config{
+			Services:       []Service{{Name: "api", HeartbeatTimeoutDuration: time.Minute}},
+			StateDirectory: stateDir,
+		}
+	}
+
+	stateDir := t.TempDir()
+	manager, err := NewManager(newConfig(stateDir))
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	monitoredSince := time.Now().Add(-2 * time.Hour)
+	manager.lookup["api"].MonitoredSince = monitoredSince
+	manager.lookup["api"].LastPulse = time.Now().Add(-time.Hour)
+	manager.UpdatePulse("api")
+
+	restarted, err := NewManager(newConfig(stateDir))
+	if err != nil {
+		t.Fatalf("failed to recreate manager: %v", err)
+	}
+
+	report := restarted.UptimeReport()
+	if len(report.Services) != 1 || !report.Services[0].Since.Equal(monitoredSince) {
+		t.Fatalf("expected monitoring start to be restored, got %+v", report.Services)
+	}
+	if stats := report.Services[0].Windows["24h"]; stats.Incidents != 1 || stats.Downtime != "59m0s" || stats.UptimePercent >= 100 {
+		t.Errorf("expected the incident to be restored, got %+v", stats)
+	}
+
+	summary := restarted.uptimeSummary(7 * 24 * time.Hour)
+	if !strings.Contains(summary, "api: ") || !strings.Contains(summary, "1 incidents") || !strings.Contains(summary, "MTTR 59m0s") {
+		t.Errorf("unexpected summary: %q", summary)
+	}
+}
+
+func TestSummaryReport(t *testing.T) {
+	now := time.Now()
+	manager, _ := NewManager(&Config{
+		Services: []Service{
+			{Name: "web", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "api", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "backup", HeartbeatTimeoutDuration: time.Minute, Tags: []string{"backup"}},
+		},
+	})
+	manager.lastSummary = now.Add(-24 * time.Hour)
+
+	manager.lookup["web"].addIncident(Incident{Start: now.Add(-48 * time.Hour), End: now.Add(-47 * time.Hour)})
+	manager.lookup["web"].addIncident(Incident{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)})
+	manager.lookup["api"].LastPulse = now.Add(-time.Hour)
+	manager.lookup["api"].IncidentStart = now.Add(-time.Hour)
+	if _, err := manager.Acknowledge("api", "deploy in progress", 0); err != nil {
+		t.Fatalf("failed to acknowledge: %v", err)
+	}
+	if _, err := manager.CreateSilence(Silence{Tags: []string{"backup"}, StartsAt: now.Add(-time.Minute)}, time.Hour); err != nil {
+		t.Fatalf("failed to create silence: %v", err)
+	}
+
+	report := manager.summaryReport(now)
+	if report.Title != "Service Uptime Center summary: 2 of 3 services healthy" {
+		t.Errorf("unexpected title: %q", report.Title)
+	}
+	for _, expected := range []string{
+		"web, ok, " + manager.lookup["web"].LastPulse.Format(time.RFC3339) + ", 1\n",
+		"api, down, " + manager.lookup["api"].LastPulse.Format(time.RFC3339) + ", 1\n",
+		"Silenced (1):\nbackup (silence ",
+		"Acknowledged (1):\napi: deploy in progress\n",
+	} {
+		if !strings.Contains(report.Body, expected) {
+			t.Errorf("expected body to contain %q, got:\n%s", expected, report.Body)
+		}
+	}
+	if !manager.lastSummary.Equal(now) {
+		t.Errorf("expected the report to be marked as sent")
+	}
+
+	manager.UpdatePulse("api")
+	if report := manager.summaryReport(now.Add(time.Minute)); report.Title != "Service Uptime Center running without any issues." || !strings.Contains(report.Body, "web, ok, "+manager.lookup["web"].LastPulse.Format(time.RFC3339)+", 0\n") {
+		t.Errorf("unexpected follow-up report: %q\n%s", report.Title, report.Body)
+	}
+
+	manager.lookup["backup"].LastPulse = now.Add(-time.Hour)
+	if _, err := manager.Pause("backup", "disk replacement", false); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if report := manager.summaryReport(now.Add(time.Minute)); report.Title != "Service Uptime Center running without any issues." || !strings.Contains(report.Body, "Paused (1):\nbackup: disk replacement\n") {
+		t.Errorf("expected the paused service to be listed without counting as unhealthy: %q\n%s", report.Title, report.Body)
+	}
+
+	settings := SummaryReportSettings{Schedule: "0 9 * * 1"}
+	if err := settings.validate(); err != nil {
+		t.Fatalf("failed to validate schedule: %v", err)
+	}
+	monday := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	if next := settings.next(monday.Add(-time.Hour), time.Hour); !next.Equal(monday) {
+		t.Errorf("expected next report at %s, got %s", monday, next)
+	}
+	if next := (&SummaryReportSettings{}).next(monday, time.Hour); !next.Equal(monday.Add(time.Hour)) {
+		t.Errorf("expected the interval fallback, got %s", next)
+	}
+	if err := (&SummaryReportSettings{Schedule: "every day"}).validate(); !errors.Is(err, apperror.ErrInvalidSummaryReport) {
+		t.Errorf("expected ErrInvalidSummaryReport, got %v", err)
+	}
+}
+
+func TestLateState(t *testing.T) {
+	now := time.Now()
+	service := &Service{Name: "api", HeartbeatTimeoutDuration: 10 * time.Minute, WarningThreshold: 0.8, LastPulse: now}
+
+	for _, tc := range []struct {
+		sinceLastPulse time.Duration
+		expected       State
+	}{
+		{7 * time.Minute, StateOK},
+		{8 * time.Minute, StateLate},
+		{10 * time.Minute, StateDown},
+	} {
+		if state := service.state(now.Add(tc.sinceLastPulse)); state != tc.expected {
+			t.Errorf("expected %s after %s, got %s", tc.expected, tc.sinceLastPulse, state)
+		}
+	}
+
+	service.WarningThreshold = 0
+	if state := service.state(now.Add(9 * time.Minute)); state != StateOK {
+		t.Errorf("expected services without a threshold to never be late, got %s", state)
+	}
+	service.Pause = &Pause{}
+	if state := service.state(now.Add(time.Hour)); state != StatePaused {
+		t.Errorf("expected paused, got %s", state)
+	}
+
+	for _, threshold := range []float64{-0.5, 1, 1.5} {
+		invalid := Service{Name: "api", HeartbeatTimeoutDuration: time.Minute, WarningThreshold: threshold}
+		if err := invalid.validate(); !errors.Is(err, apperror.ErrInvalidWarningThreshold) {
+			t.Errorf("expected ErrInvalidWarningThreshold for %v, got %v", threshold, err)
+		}
+	}
+}
+
+func TestLateServicesWarning(t *testing.T) {
+	manager, _ := NewManager(&Config{
+		Services: []Service{
+			{Name: "web", HeartbeatTimeoutDuration: 10 * time.Minute, WarningThreshold: 0.5},
+			{Name: "backup", HeartbeatTimeoutDuration: 10 * time.Minute, WarningThreshold: 0.5, Tags: []string{"backup"}},
+			{Name: "api", HeartbeatTimeoutDuration: 10 * time.Minute},
+		},
+		Routes: []Route{{Tags: []string{"backup"}, Notifiers: []string{"gotify"}, WarningNotifiers: []string{"ntfy"}}},
+	})
+	for _, service := range manager.services {
+		service.LastPulse = time.Now().Add(-6 * time.Minute)
+	}
+
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	outages := notification.ProtocolTargets{Primary: []string{"gotify"}}
+	manager.handleLateServices(notificationManager, targets.Primary, outages)
+	manager.handleLateServices(notificationManager, targets.Primary, outages)
+
+	notifications := sent()
+	if len(notifications) != 1 || notifications[0].title != "2 services are late" {
+		t.Fatalf("expected a single warning for both late services, got %+v", notifications)
+	}
+	if strings.Contains(notifications[0].body, "api") {
+		t.Errorf("expected service without a warning threshold to be left out, got %q", notifications[0].body)
+	}
+
+	events, _, _ := manager.Events("web", EventQuery{})
+	if len(events) != 2 || events[0].Type != EventNotification || events[1].Type != EventLate {
+		t.Errorf("expected late and notification events, got %+v", events)
+	}
+
+	manager.UpdatePulse("web")
+	manager.handleLateServices(notificationManager, targets.Primary, outages)
+	manager.lookup["web"].LastPulse = time.Now().Add(-6 * time.Minute)
+	manager.handleLateServices(notificationManager, targets.Primary, outages)
+	if notifications := sent(); len(notifications) != 2 || notifications[1].title != "1 services are late" {
+		t.Errorf("expected a new warning once the service was late again, got %+v", notifications)
+	}
+
+	backup := manager.lookup["backup"]
+	if diff := cmp.Diff(notification.ProtocolTargets{Primary: []string{"ntfy"}}, backup.warningTargets(manager.cfg.Routes, nil, outages), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("route warning targets mismatch (-want +got):\n%s", diff)
+	}
+	web := manager.lookup["web"]
+	if diff := cmp.Diff(outages, web.warningTargets(manager.cfg.Routes, nil, outages), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("expected warnings to fall back to the outage targets (-want +got):\n%s", diff)
+	}
+}
+
+func TestMissedIntervalsPolicy(t *testing.T) {
+	manager, _ := NewManager(&Config{
+		Services: []Service{{Name: "cron", HeartbeatTimeoutDuration: time.Minute, MissedIntervals: 3}},
+	})
+	service := manager.lookup["cron"]
+
+	service.LastPulse = time.Now().Add(-2*time.Minute - 30*time.Second)
+	if service.isProblematic() {
+		t.Error("expected two missed intervals to be tolerated")
+	}
+
+	service.LastPulse = time.Now().Add(-3 * time.Minute)
+	if !service.isProblematic() {
+		t.Error("expected the service to be down after three missed intervals")
+	}
+	if downSince := service.downSince(); !downSince.Equal(service.LastPulse.Add(3 * time.Minute)) {
+		t.Errorf("unexpected down since: %s", downSince)
+	}
+}
+
+func TestMinPulsesPolicy(t *testing.T) {
+	manager, _ := NewManager(&Config{
+		Services: []Service{{Name: "worker", HeartbeatTimeoutDuration: time.Hour, MinPulses: 3, MinPulsesWindow: 10 * time.Minute}},
+	})
+	service := manager.lookup["worker"]
+	now := time.Now()
+
+	if service.isProblematic() {
+		t.Fatal("expected a full window before the min pulses policy applies")
+	}
+
+	service.resetPulseHistory(now.Add(-time.Hour))
+	for _, ago := range []time.Duration{9 * time.Minute, 5 * time.Minute} {
+		service.recordPulse(now.Add(-ago))
+	}
+	service.LastPulse = now.Add(-5 * time.Minute)
+	if !service.isProblematic() || service.state(now) != StateDown {
+		t.Fatal("expected the service to be down with only two pulses within the window")
+	}
+
+	manager.UpdatePulse("worker")
+	if service.isProblematic() || !service.IncidentStart.IsZero() {
+		t.Fatalf("expected the third pulse to resolve the incident, incident start: %s", service.IncidentStart)
+	}
+	if recent := service.recentPulses(time.Now()); recent != 3 {
+		t.Errorf("expected 3 recent pulses, got %d", recent)
+	}
+	if deadline := service.downSince(); !deadline.Equal(now.Add(-9 * time.Minute).Add(10 * time.Minute)) {
+		t.Errorf("expected the service to go down once the oldest pulse leaves the window, got %s", deadline)
+	}
+
+	service.recordPulse(now.Add(time.Hour))
+	if len(service.pulses) != 1 {
+		t.Errorf("expected pulses outside of the window to be dropped, got %d", len(service.pulses))
+	}
+
+	for _, invalid := range []Service{
+		{Name: "worker", HeartbeatTimeoutDuration: time.Minute, MissedIntervals: -1},
+		{Name: "worker", HeartbeatTimeoutDuration: time.Minute, MinPulses: 2},
+	} {
+		if err := invalid.validate(); !errors.Is(err, apperror.ErrInvalidPulsePolicy) {
+			t.Errorf("expected ErrInvalidPulsePolicy for %+v, got %v", invalid, err)
+		}
+	}
+}
+
+func TestEnablingMinPulses(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	services := []Service{{Name: "worker", HeartbeatTimeoutDuration: time.Hour}}
+	manager, _ := NewManagerWithClock(&Config{Services: services}, fake)
+	if _, err := manager.CreateService(ServiceDefinition{Name: "ci-job", HeartbeatTimeoutDuration: "1h"}); err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	for range 12 {
+		fake.Advance(10 * time.Minute)
+		manager.UpdatePulse("worker")
+		manager.UpdatePulse("ci-job")
+	}
+
+	if _, err := manager.UpdateService("ci-job", ServiceDefinition{HeartbeatTimeoutDuration: "1h", MinPulses: 2, MinPulsesWindow: "1h"}); err != nil {
+		t.Fatalf("failed to update service: %v", err)
+	}
+	err := manager.Reload(&Config{
+		Services: []Service{{Name: "worker", HeartbeatTimeoutDuration: time.Hour, MinPulses: 2, MinPulsesWindow: time.Hour}},
+	}, MonitoringInstructions{})
+	if err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	for _, name := range []string{"worker", "ci-job"} {
+		service := manager.lookup[name]
+		if service.isProblematic() {
+			t.Errorf("%s: expected a full window before the enabled min pulses policy applies", name)
+		}
+		if deadline := service.downSince(); !deadline.Equal(fake.Now().Add(time.Hour)) {
+			t.Errorf("%s: expected the service to go down a window after the policy was enabled, got %s", name, deadline)
+		}
+	}
+}
+
+func TestMetricRules(t *testing.T) {
+	minBytes := 1.0
+	manager, _ := NewManager(&Config{
+		Services: []Service{{
+			Name:                     "restic-bkp",
+			HeartbeatTimeoutDuration: 24 * time.Hour,
+			MetricRules: []MetricRule{
+				{Metric: "bytes_added", Min: &minBytes},
+				{Metric: "duration_s", MaxDeviation: 0.5, Samples: 3},
+			},
+		}},
+	})
+	service := manager.lookup["restic-bkp"]
+
+	for _, duration := range []float64{400, 420, 380} {
+		manager.UpdatePulseWithMetrics("restic-bkp", map[string]float64{"bytes_added": 123, "duration_s": duration})
+	}
+	if service.isProblematic() {
+		t.Fatalf("expected metrics within the rules to pass, got %v", service.metricViolations)
+	}
+
+	manager.UpdatePulseWithMetrics("restic-bkp", map[string]float64{"bytes_added": 0, "duration_s": 1000})
+	if !service.isProblematic() || service.state(time.Now()) != StateDown || service.IncidentStart.IsZero() {
+		t.Fatal("expected the violating pulse to open an incident")
+	}
+	expected := []string{
+		"bytes_added is 0, below the minimum of 1",
+		"duration_s is 1000, 150% off the average of 400",
+	}
+	if diff := cmp.Diff(expected, service.metricViolations); diff != "" {
+		t.Errorf("violations mismatch (-want +got):\n%s", diff)
+	}
+
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	manager.handleProblematicServices(notificationManager, targets, manager.getDueProblematicServices(time.Hour), time.Hour)
+	if notifications := sent(); len(notifications) != 1 || !strings.Contains(notifications[0].body, "bytes_added is 0") {
+		t.Errorf("expected the violation to be reported, got %+v", notifications)
+	}
+
+	manager.UpdatePulse("restic-bkp")
+	if !service.isProblematic() {
+		t.Error("expected a pulse without metrics to leave the violation in place")
+	}
+
+	manager.UpdatePulseWithMetrics("restic-bkp", map[string]float64{"bytes_added": 50, "duration_s": 600})
+	if service.isProblematic() || !service.IncidentStart.IsZero() {
+		t.Errorf("expected a passing pulse to resolve the incident, got %v", service.metricViolations)
+	}
+	if diff := cmp.Diff([]float64{380, 1000, 600}, service.metricHistory["duration_s"]); diff != "" {
+		t.Errorf("history mismatch (-want +got):\n%s", diff)
+	}
+
+	events, _, _ := manager.Events("restic-bkp", EventQuery{Limit: 3})
+	if len(events) != 3 || events[0].Type != EventPulse || events[1].Type != EventIncidentResolved {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	for _, rule := range []MetricRule{
+		{Metric: "bytes_added"},
+		{Min: &minBytes},
+		{Metric: "bytes_added", Min: &minBytes, Max: new(float64)},
+		{Metric: "bytes_added", MaxDeviation: -1},
+	} {
+		invalid := Service{Name: "restic-bkp", HeartbeatTimeoutDuration: time.Minute, MetricRules: []MetricRule{rule}}
+		if err := invalid.validate(); !errors.Is(err, apperror.ErrInvalidMetricRule) {
+			t.Errorf("expected ErrInvalidMetricRule for %+v, got %v", rule, err)
+		}
+	}
+
+	if _, err := manager.Pause("restic-bkp", "migrating the repository", false); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	manager.UpdatePulseWithMetrics("restic-bkp", map[string]float64{"bytes_added": 0, "duration_s": 600})
+	if len(service.metricViolations) != 0 || !service.IncidentStart.IsZero() {
+		t.Errorf("expected the rules not to be checked while paused, got %v", service.metricViolations)
+	}
+	if service.LastMetrics["bytes_added"] != 0 {
+		t.Errorf("expected the metrics of a paused service to be kept, got %v", service.LastMetrics)
+	}
+}
+
+func TestReportCheckResult(t *testing.T) {
+	manager, _ := NewManager(&Config{
+		Services: []Service{{Name: "web", HeartbeatTimeoutDuration: time.Hour}},
+	})
+	service := manager.lookup["web"]
+
+	if err := manager.ReportCheckResult("web", "http", false, false, "unexpected status 503", nil); err != nil {
+		t.Fatalf("failed to report check result: %v", err)
+	}
+	manager.ReportCheckResult("web", "http", false, false, "unexpected status 503", nil)
+	if !service.isProblematic() || service.IncidentStart.IsZero() {
+		t.Fatal("expected a failed check to open an incident right away")
+	}
+	if diff := cmp.Diff([]string{"http check failed: unexpected status 503"}, service.problemReasons()); diff != "" {
+		t.Errorf("problem reasons mismatch (-want +got):\n%s", diff)
+	}
+
+	lastPulse := service.LastPulse
+	if err := manager.ReportCheckResult("web", "http", true, false, "", map[string]float64{"load": 0.5}); err != nil {
+		t.Fatalf("failed to report check result: %v", err)
+	}
+	if service.isProblematic() || !service.IncidentStart.IsZero() || !service.LastPulse.After(lastPulse) {
+		t.Error("expected a successful check to count as a pulse and resolve the incident")
+	}
+	if diff := cmp.Diff(map[string]float64{"load": 0.5}, service.LastMetrics); diff != "" {
+		t.Errorf("metrics mismatch (-want +got):\n%s", diff)
+	}
+
+	events, _, _ := manager.Events("web", EventQuery{})
+	var types []EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	expected := []EventType{EventPulse, EventIncidentResolved, EventIncidentOpened, EventCheckFailed}
+	if diff := cmp.Diff(expected, types); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := manager.ReportCheckResult("missing", "http", true, false, "", nil); !errors.Is(err, apperror.ErrServiceNotFound) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+
+	if _, err := manager.Pause("web", "maintenance", false); err != nil {
+		t.Fatalf("failed to pause: %v", err)
+	}
+	if err := manager.ReportCheckResult("web", "http", false, false, "connection refused", nil); err != nil {
+		t.Fatalf("failed to report check result: %v", err)
+	}
+	if service.checks["http"].failure != "" || !service.IncidentStart.IsZero() {
+		t.Error("expected check results of a paused service to be ignored")
+	}
+}
+
+func TestCommandCheckStatus(t *testing.T) {
+	manager, _ := NewManager(&Config{
+		Services: []Service{{Name: "backup", HeartbeatTimeoutDuration: time.Hour}},
+	})
+	cfg := checker.Config{Checks: []checker.Check{{
+		Service:  "backup",
+		Interval: 10 * time.Millisecond,
+		Command:  &checker.CommandCheck{Command: []string{"sh", "-c", "echo 'DISK OK - free space: / 3326 MB (56%) | /=2643MB;5948;5958;0;5968'"}},
+	}}}
+	if err := cfg.Validate(map[string]struct{}{"backup": {}}); err != nil {
+		t.Fatalf("invalid check: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	checker.NewScheduler(&cfg, manager).Run(ctx)
+
+	status, _ := manager.GetStatusJSON(StatusFilter{})
+	if !strings.Contains(string(status), `"check_status":{"command":"DISK OK - free space: / 3326 MB (56%)"}`) {
+		t.Errorf("expected the output of the command check in the status, got %s", status)
+	}
+	if strings.Contains(string(status), "check_warnings") {
+		t.Errorf("expected the output of a passing check not to be a warning, got %s", status)
+	}
+	if manager.lookup["backup"].LastMetrics["/"] != 2643 {
+		t.Errorf("expected the performance data as metrics, got %v", manager.lookup["backup"].LastMetrics)
+	}
+}
+
+func TestCheckWarnings(t *testing.T) {
+	manager, _ := NewManager(&Config{
+		Services: []Service{{Name: "web", HeartbeatTimeoutDuration: time.Hour}},
+	})
+	notificationManager, targets, sent := newNtfyRecorder(t)
+
+	warning := "certificate example.com expires within 14 days, on 2026-11-01"
+	for range 2 {
+		manager.ReportCheckResult("web", "tls", true, true, warning, nil)
+		manager.handleCheckWarnings(notificationManager, targets.Primary, notification.ProtocolTargets{})
+	}
+	notifications := sent()
+	if len(notifications) != 1 || !strings.Contains(notifications[0].body, "web: "+warning) {
+		t.Fatalf("expected a single warning notification, got %+v", notifications)
+	}
+	if manager.lookup["web"].isProblematic() {
+		t.Error("expected a warning not to make the service problematic")
+	}
+
+	manager.ReportCheckResult("web", "tls", true, true, "certificate example.com expires within 7 days, on 2026-11-01", nil)
+	manager.handleCheckWarnings(notificationManager, targets.Primary, notification.ProtocolTargets{})
+	if notifications := sent(); len(notifications) != 2 {
+		t.Errorf("expected a new notification once the next threshold was crossed, got %+v", notifications)
+	}
+
+	manager.ReportCheckResult("web", "tls", true, false, "", nil)
+	if status, _ := manager.GetStatusJSON(StatusFilter{}); strings.Contains(string(status), "check_warnings") {
+		t.Errorf("expected the warning to be cleared, got %s", status)
+	}
+}
+
+func TestReportResultsOfSeveralChecks(t *testing.T) {
+	manager, _ := NewManager(&Config{
+		Services: []Service{{Name: "web", HeartbeatTimeoutDuration: time.Hour}},
+	})
+	service := manager.lookup["web"]
+	notificationManager, targets, sent := newNtfyRecorder(t)
+
+	manager.ReportCheckResult("web", "http", false, false, "unexpected status 503", nil)
+	manager.ReportCheckResult("web", "tls", true, false, "", nil)
+	if !service.isProblematic() || service.IncidentStart.IsZero() {
+		t.Fatal("expected a passing check not to clear the failure of another check")
+	}
+	if diff := cmp.Diff([]string{"http check failed: unexpected status 503"}, service.problemReasons()); diff != "" {
+		t.Errorf("problem reasons mismatch (-want +got):\n%s", diff)
+	}
+
+	warning := "certificate example.com expires within 14 days, on 2026-11-01"
+	for range 2 {
+		manager.ReportCheckResult("web", "tls", true, true, warning, nil)
+		manager.handleCheckWarnings(notificationManager, targets.Primary, notification.ProtocolTargets{})
+		manager.ReportCheckResult("web", "http", true, false, "", nil)
+		manager.handleCheckWarnings(notificationManager, targets.Primary, notification.ProtocolTargets{})
+	}
+	if notifications := sent(); len(notifications) != 1 {
+		t.Errorf("expected the warning to be sent once despite the other check passing, got %+v", notifications)
+	}
+	if service.isProblematic() || !service.IncidentStart.IsZero() {
+		t.Error("expected the service to recover once every check passed")
+	}
+
+	manager.ReportCheckResult("web", "http", false, false, "unexpected status 503", nil)
+	manager.RetainChecks(map[string][]string{"web": {"tls"}})
+	if service.isProblematic() || !service.IncidentStart.IsZero() {
+		t.Error("expected the failure of a removed check to be dropped")
+	}
+	if service.checks["tls"].warning != warning {
+		t.Error("expected the state of a retained check to be kept")
+	}
+}
+
+func TestNextDeadline(t *testing.T) {
+	now := time.Now()
+	pulse := now.Add(-10 * time.Minute)
+	for _, test := range []struct {
+		name     string
+		service  Service
+		expected time.Time
+	}{
+		{"heartbeat timeout", Service{HeartbeatTimeoutDuration: time.Hour, LastPulse: pulse}, pulse.Add(time.Hour)},
+		{"late", Service{HeartbeatTimeoutDuration: time.Hour, WarningThreshold: 0.5, LastPulse: pulse}, pulse.Add(30 * time.Minute)},
+		{"late notified", Service{HeartbeatTimeoutDuration: time.Hour, WarningThreshold: 0.5, LastPulse: pulse, lateNotified: true}, pulse.Add(time.Hour)},
+		{"missed intervals", Service{HeartbeatTimeoutDuration: time.Hour, MissedIntervals: 3, LastPulse: pulse}, pulse.Add(3 * time.Hour)},
+		{"newly down", Service{HeartbeatTimeoutDuration: time.Minute, LastPulse: pulse}, pulse.Add(time.Minute)},
+		{"still down", Service{HeartbeatTimeoutDuration: time.Minute, LastPulse: pulse, LastProblem: now.Add(-time.Minute)}, now.Add(5 * time.Minute)},
+		{"check warning", Service{HeartbeatTimeoutDuration: time.Hour, LastPulse: pulse, checks: map[string]*checkState{"tls": {warning: "expires soon"}}}, now},
+		{"paused", Service{HeartbeatTimeoutDuration: time.Minute, LastPulse: pulse, Pause: &Pause{}}, time.Time{}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			notSuppressed := func() (time.Time, bool) { return time.Time{}, false }
+			if deadline := test.service.nextDeadline(now, 6*time.Minute, notSuppressed); !deadline.Equal(test.expected) {
+				t.Errorf("expected deadline %v, got %v", test.expected, deadline)
+			}
+		})
+	}
+}
+
+func TestSuppressionEndsAreDeadlines(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	manager, _ := NewManagerWithClock(&Config{
+		Services: []Service{
+			{Name: "web", HeartbeatTimeoutDuration: 10 * time.Minute},
+			{Name: "db", HeartbeatTimeoutDuration: 10 * time.Minute},
+		},
+	}, fake)
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	instr := MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: 6 * time.Hour},
+		Notifiers: targets,
+	}
+	if _, err := manager.CreateSilence(Silence{Services: []string{"web"}}, 30*time.Minute); err != nil {
+		t.Fatalf("failed to create silence: %v", err)
+	}
+
+	fake.Advance(10 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if notifications := sent(); len(notifications) != 1 || strings.Contains(notifications[0].body, "web") {
+		t.Fatalf("expected only db to be reported while web is silenced, got %+v", notifications)
+	}
+	if _, err := manager.Acknowledge("db", "", 30*time.Minute); err != nil {
+		t.Fatalf("failed to acknowledge: %v", err)
+	}
+
+	for i, expected := range []struct {
+		deadline time.Time
+		service  string
+	}{
+		{start.Add(30 * time.Minute), "web"},
+		{start.Add(40 * time.Minute), "db"},
+	} {
+		deadline := manager.nextDeadline(instr.Timings.IncidentsPollFreq)
+		if !deadline.Equal(expected.deadline) {
+			t.Fatalf("expected the next deadline when the suppression of %s ends at %v, got %v", expected.service, expected.deadline, deadline)
+		}
+		fake.Set(deadline)
+		manager.evaluate(notificationManager, instr)
+		if notifications := sent(); len(notifications) != i+2 || !strings.Contains(notifications[i+1].body, expected.service) {
+			t.Fatalf("expected %s to be reported once its suppression ended, got %+v", expected.service, notifications)
+		}
+	}
+}
+
+func TestMonitoringReportsAtDeadline(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	manager, _ := NewManagerWithClock(&Config{
+		Services: []Service{
+			{Name: "web", HeartbeatTimeoutDuration: 10 * time.Minute},
+			{Name: "db", HeartbeatTimeoutDuration: time.Hour},
+		},
+	}, fake)
+	notificationManager, targets, sent := newNtfyRecorder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.StartMonitoring(ctx, notificationManager, MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: 6 * time.Hour, SuccessfulReportCooldown: 24 * time.Hour},
+		Notifiers: targets,
+	})
+	waitForNotifications := func(count int) []sentNotification {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for len(sent()) < count && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		return sent()
+	}
+
+	// Once the monitor waits for the deadline of web, nothing can be sent before it.
+	fake.WaitForTimer(start.Add(10 * time.Minute))
+	fake.Advance(9 * time.Minute)
+	if notifications := sent(); len(notifications) != 0 {
+		t.Fatalf("expected no notification before the timeout, got %+v", notifications)
+	}
+
+	fake.Advance(time.Minute)
+	if notifications := waitForNotifications(1); len(notifications) != 1 {
+		t.Fatalf("expected the outage to be reported when the timeout expires, got %+v", notifications)
+	}
+
+	// A pulse moves the deadline, the service is reported again once it expires.
+	manager.UpdatePulse("web")
+	fake.WaitForTimer(fake.Now().Add(10 * time.Minute))
+	fake.Advance(9 * time.Minute)
+	if notifications := sent(); len(notifications) != 1 {
+		t.Fatalf("expected a single notification before the new deadline, got %d", len(notifications))
+	}
+	fake.Advance(time.Minute)
+	notifications := waitForNotifications(2)
+	if len(notifications) != 2 || !strings.Contains(notifications[1].body, "web") {
+		t.Errorf("expected the second outage to be reported, got %+v", notifications)
+	}
+}
+
+func TestIncidentLifecycle(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	manager, _ := NewManagerWithClock(&Config{
+		Services: []Service{{Name: "web", HeartbeatTimeoutDuration: 10 * time.Minute, WarningThreshold: 0.5}},
+	}, fake)
+	service := manager.lookup["web"]
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	instr := MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: time.Hour, ProblematicReportCooldown: 30 * time.Minute},
+		Notifiers: targets,
+	}
+	titles := func() []string {
+		var titles []string
+		for _, notification := range sent() {
+			titles = append(titles, notification.title)
+		}
+		return titles
+	}
+
+	fake.Advance(4 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if len(sent()) != 0 || service.state(fake.Now()) != StateOK {
+		t.Fatalf("expected the service to be fine, got %s with %v", service.state(fake.Now()), titles())
+	}
+	if deadline := manager.nextDeadline(time.Hour); !deadline.Equal(start.Add(5 * time.Minute)) {
+		t.Errorf("expected the next deadline when the service gets late, got %v", deadline)
+	}
+
+	fake.Advance(time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if diff := cmp.Diff([]string{"1 services are late"}, titles()); diff != "" {
+		t.Fatalf("notifications mismatch (-want +got):\n%s", diff)
+	}
+
+	fake.Advance(5 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if !service.IncidentStart.Equal(start.Add(10 * time.Minute)) {
+		t.Errorf("expected the incident to start when the timeout expired, got %v", service.IncidentStart)
+	}
+	if diff := cmp.Diff([]string{"1 services are late", "Problem detected with 1 services"}, titles()); diff != "" {
+		t.Fatalf("notifications mismatch (-want +got):\n%s", diff)
+	}
+
+	// Not due again before the poll frequency passed.
+	fake.Advance(30 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if len(sent()) != 2 {
+		t.Fatalf("expected no repeated report before the poll frequency passed, got %v", titles())
+	}
+	fake.Advance(30 * time.Minute)
+	manager.evaluate(notificationManager, instr)
+	if len(sent()) != 3 || !service.LastProblemReported.Equal(fake.Now()) {
+		t.Fatalf("expected the report to be repeated after the poll frequency, got %v", titles())
+	}
+
+	fake.Advance(10 * time.Minute)
+	manager.UpdatePulse("web")
+	manager.evaluate(notificationManager, instr)
+	if diff := cmp.Diff([]Incident{{Start: start.Add(10 * time.Minute), End: start.Add(80 * time.Minute)}}, service.incidents); diff != "" {
+		t.Errorf("incidents mismatch (-want +got):\n%s", diff)
+	}
+	expected := UptimeStats{UptimePercent: 12.5, Incidents: 1, Downtime: "1h10m0s", MeanTimeToRecovery: "1h10m0s"}
+	if diff := cmp.Diff(expected, service.uptime(fake.Now(), 24*time.Hour)); diff != "" {
+		t.Errorf("uptime mismatch (-want +got):\n%s", diff)
+	}
+	if len(sent()) != 3 {
+		t.Errorf("expected no notification after the recovery, got %v", titles())
+	}
+
+	report := manager.summaryReport(fake.Now())
+	if !strings.Contains(report.Body, "web, ok, 2026-10-01T13:20:00Z, 1") {
+		t.Errorf("expected the summary to list the resolved incident, got %q", report.Body)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(&Config{
+		Services:       []Service{{Name: "web", HeartbeatTimeoutDuration: time.Hour}},
+		StateDirectory: dir,
+		Events:         EventSettings{Persist: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	notificationManager, targets, _ := newNtfyRecorder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager.StartMonitoring(ctx, notificationManager, MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: time.Hour, SuccessfulReportCooldown: time.Hour},
+		Notifiers: targets,
+	})
+
+	// Monitoring keeps running until its context is done.
+	timeout, cancelTimeout := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelTimeout()
+	if err := manager.Shutdown(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected shutdown to time out while monitoring is running, got %v", err)
+	}
+
+	cancel()
+	if err := manager.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected a clean shutdown, got %v", err)
+	}
+	if manager.eventLog != nil {
+		t.Error("expected the event log to be closed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "uptime.json")); err != nil {
+		t.Errorf("expected uptime statistics to be saved: %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	manager, err := NewManagerWithClock(&Config{
+		Services: []Service{
+			{Name: "web", HeartbeatTimeoutDuration: time.Minute},
+			{Name: "db", HeartbeatTimeoutDuration: time.Minute},
+		},
+	}, fake)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if _, err := manager.CreateService(ServiceDefinition{Name: "ci-job", HeartbeatTimeoutDuration: "1h"}); err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	fake.Advance(2 * time.Minute)
+	downSince := manager.lookup["web"].downSince()
+
+	for _, test := range []struct {
+		name        string
+		services    []Service
+		expectError error
+	}{
+		{"duplicate names", []Service{{Name: "web"}, {Name: "web"}}, apperror.ErrDuplicateServiceNames},
+		{"dynamic name", []Service{{Name: "ci-job"}}, apperror.ErrDuplicateServiceNames},
+		{"unknown dependency", []Service{{Name: "web", DependsOn: []string{"cache"}}}, apperror.ErrUnknownDependency},
+	} {
+		if err := manager.Reload(&Config{Services: test.services}, MonitoringInstructions{}); !errors.Is(err, test.expectError) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expectError, err)
+		}
+	}
+	if len(manager.services) != 3 || manager.lookup["web"].HeartbeatTimeoutDuration != time.Minute {
+		t.Fatalf("expected a failed reload to change nothing, got %+v", manager.services)
+	}
+
+	err = manager.Reload(&Config{
+		Services: []Service{
+			{Name: "web", HeartbeatTimeoutDuration: time.Hour},
+			{Name: "cache", HeartbeatTimeoutDuration: time.Minute},
+		},
+	}, MonitoringInstructions{})
+	if err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+
+	var names []string
+	for _, service := range manager.services {
+		names = append(names, service.Name)
+	}
+	if diff := cmp.Diff([]string{"web", "cache", "ci-job"}, names); diff != "" {
+		t.Errorf("unexpected services (-want +got):\n%s", diff)
+	}
+	if _, exists := manager.lookup["db"]; exists {
+		t.Error("expected removed service to be dropped")
+	}
+
+	web := manager.lookup["web"]
+	if web.HeartbeatTimeoutDuration != time.Hour {
+		t.Errorf("expected the new timeout to apply, got %v", web.HeartbeatTimeoutDuration)
+	}
+	if !web.LastPulse.Before(downSince) {
+		t.Errorf("expected the kept service to keep its last pulse, got %v", web.LastPulse)
+	}
+	if cache := manager.lookup["cache"]; !cache.LastPulse.Equal(fake.Now()) || cache.isProblematic() {
+		t.Errorf("expected the added service to start out healthy, got %+v", cache)
+	}
+}
+
+func TestWeeklyUptimeDue(t *testing.T) {
+	// A Thursday, the schedules send their reports at 9:00.
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	for _, test := range []struct {
+		name     string
+		schedule string
+		expected []int
+	}{
+		{"daily", "0 9 * * *", []int{6, 13}},
+		{"weekly", "0 9 * * 1", []int{4, 11}},
+	} {
+		manager, _ := NewManager(&Config{
+			SummaryReport: SummaryReportSettings{Schedule: test.schedule},
+			Uptime:        UptimeSettings{WeeklySummary: true},
+		})
+		if err := manager.cfg.SummaryReport.validate(); err != nil {
+			t.Fatalf("%s: invalid schedule: %v", test.name, err)
+		}
+		manager.instr = MonitoringInstructions{Timings: &timings.Timings{SuccessfulReportCooldown: 24 * time.Hour}}
+		manager.lastWeeklyUptime = start
+
+		var due []int
+		for report := manager.cfg.SummaryReport.next(start, 0); report.Before(start.AddDate(0, 0, 15)); report = manager.cfg.SummaryReport.next(report, 0) {
+			if manager.weeklyUptimeDue(report) {
+				due = append(due, int(report.Sub(start)/(24*time.Hour)))
+			}
+		}
+		if diff := cmp.Diff(test.expected, due); diff != "" {
+			t.Errorf("%s: days the weekly uptime is due mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+}
+
+func TestReloadSummarySchedule(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	manager, _ := NewManagerWithClock(&Config{
+		Services: []Service{{Name: "web", HeartbeatTimeoutDuration: 24 * time.Hour}},
+	}, fake)
+	notificationManager, targets, sent := newNtfyRecorder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	manager.StartMonitoring(ctx, notificationManager, MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: 48 * time.Hour, SuccessfulReportCooldown: 7 * 24 * time.Hour},
+		Notifiers: targets,
+	})
+	fake.WaitForTimer(start.Add(7 * 24 * time.Hour))
+
+	err := manager.Reload(&Config{Services: []Service{{Name: "web", HeartbeatTimeoutDuration: 24 * time.Hour}}}, MonitoringInstructions{
+		Timings:   &timings.Timings{IncidentsPollFreq: 48 * time.Hour, SuccessfulReportCooldown: time.Hour},
+		Notifiers: targets,
+	})
+	if err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	fake.WaitForTimer(start.Add(time.Hour))
+	fake.Advance(time.Hour)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sent()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if notifications := sent(); len(notifications) != 1 || !strings.Contains(notifications[0].title, "without any issues") {
+		t.Errorf("expected the summary report to follow the reloaded cooldown, got %+v", notifications)
+	}
+}
+
+func TestReloadDisablingFlapping(t *testing.T) {
+	services := []Service{{Name: "flaky", HeartbeatTimeoutDuration: time.Minute}}
+	manager, _ := NewManager(&Config{Services: services, Flapping: FlappingSettings{Window: time.Hour, Threshold: 2}})
+	flaky := manager.lookup["flaky"]
+	for range 2 {
+		flaky.LastPulse = time.Now().Add(-2 * time.Minute)
+		manager.UpdatePulse("flaky")
+	}
+	if !flaky.isFlapping() {
+		t.Fatalf("expected service to be flapping after %d transitions", len(flaky.transitions))
+	}
+
+	if err := manager.Reload(&Config{Services: services}, MonitoringInstructions{}); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if flaky.isFlapping() || len(flaky.transitions) != 0 || flaky.flappingNotified {
+		t.Fatalf("expected flapping state to be reset once flapping detection is disabled, got %+v", flaky)
+	}
+
+	notificationManager, targets, sent := newNtfyRecorder(t)
+	flaky.LastPulse = time.Now().Add(-2 * time.Minute)
+	manager.handleProblematicServices(notificationManager, targets, manager.getDueProblematicServices(time.Hour), time.Hour)
+	if notifications := sent(); len(notifications) != 1 || !strings.Contains(notifications[0].body, "flaky") {
+		t.Errorf("expected the problem to be reported, got %+v", notifications)
+	}
+}
+
+func TestSilenceStateFollowsClock(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	manager, _ := NewManagerWithClock(&Config{Services: []Service{{Name: "api", HeartbeatTimeoutDuration: time.Hour}}}, fake)
+	silence, err := manager.CreateSilence(Silence{Services: []string{"api"}}, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create silence: %v", err)
+	}
+
+	state := func(silence *Silence) SilenceState {
+		t.Helper()
+		data, err := json.Marshal(silence)
+		if err != nil {
+			t.Fatalf("failed to marshal silence: %v", err)
+		}
+		var decoded struct {
+			State SilenceState `json:"state"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("failed to unmarshal silence: %v", err)
+		}
+		return decoded.State
+	}
+	if state(silence) != SilenceStateActive {
+		t.Errorf("expected the silence to be active, got %s", state(silence))
+	}
+	fake.Advance(2 * time.Hour)
+	if listed := manager.ListSilences(); state(&listed[0]) != SilenceStateExpired {
+		t.Errorf("expected the silence to be expired by the clock of the manager, got %s", state(&listed[0]))
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/internal/clock"
+	"service-uptime-center/internal/cron"
+)
+
+// MaintenanceWindow suppresses problematic reports for its services, and services carrying any of its tags, either
+// once, between Start and End, or every time Schedule matches, for Duration.
+type MaintenanceWindow struct {
+	Name     string        `yaml:"name"`
+	Services []string      `yaml:"services"`
+	Tags     []string      `yaml:"tags"`
+	Schedule string        `yaml:"schedule"`
+	Duration time.Duration `yaml:"duration"`
+	Start    time.Time     `yaml:"start"`
+	End      time.Time     `yaml:"end"`
+	schedule *cron.Schedule
+}
+
+func (w *MaintenanceWindow) validate(lookup map[string]struct{}) error {
+	if len(w.Name) == 0 {
+		return fmt.Errorf("%w: maintenance window is missing a name", apperror.ErrInvalidMaintenanceWindow)
+	}
+	if len(w.Services) == 0 && len(w.Tags) == 0 {
+		return fmt.Errorf("%w: %s doesn't target any services or tags", apperror.ErrInvalidMaintenanceWindow, w.Name)
+	}
+	for _, name := range w.Services {
+		if _, ok := lookup[name]; !ok {
+			return fmt.Errorf("%w: %s targets unknown service %s", apperror.ErrInvalidMaintenanceWindow, w.Name, name)
+		}
+	}
+
+	recurring := len(w.Schedule) != 0
+	oneOff := !w.Start.IsZero() || !w.End.IsZero()
+	switch {
+	case recurring && oneOff:
+		return fmt.Errorf("%w: %s has both a schedule and a start/end, pick one", apperror.ErrInvalidMaintenanceWindow, w.Name)
+	case recurring:
+		if w.Duration <= 0 {
+			return fmt.Errorf("%w: %s has a schedule but no positive duration", apperror.ErrInvalidMaintenanceWindow, w.Name)
+		}
+		schedule, err := cron.Parse(w.Schedule)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", apperror.ErrInvalidMaintenanceWindow, w.Name, err)
+		}
+		w.schedule = schedule
+	case oneOff:
+		if !w.End.After(w.Start) {
+			return fmt.Errorf("%w: %s must end after it starts", apperror.ErrInvalidMaintenanceWindow, w.Name)
+		}
+	default:
+		return fmt.Errorf("%w: %s needs either a schedule or a start and end", apperror.ErrInvalidMaintenanceWindow, w.Name)
+	}
+
+	return nil
+}
+
+// activeUntil reports whether the window is active and, if it is, when it ends.
+func (w *MaintenanceWindow) activeUntil(now time.Time) (time.Time, bool) {
+	if w.schedule != nil {
+		start, active := w.schedule.ActiveWindow(now, w.Duration)
+		return start.Add(w.Duration), active
+	}
+	return w.End, !now.Before(w.Start) && now.Before(w.End)
+}
+
+func (w *MaintenanceWindow) matches(service *Service) bool {
+	return slices.Contains(w.Services, service.Name) || service.hasAnyTag(w.Tags)
+}
+
+type SilenceState string
+
+const (
+	SilenceStatePending SilenceState = "pending"
+	SilenceStateActive  SilenceState = "active"
+	SilenceStateExpired SilenceState = "expired"
+)
+
+// Silence is an ad-hoc, API managed suppression of problematic reports for a set of services and tags.
+type Silence struct {
+	ID        string    `json:"id"`
+	Services  []string  `json:"services,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedAt time.Time `json:"created_at"`
+	// clock is set by the manager and used for the state shown in JSON, silences without one use the wall clock.
+	clock clock.Clock
+}
+
+func (s *Silence) MarshalJSON() ([]byte, error) {
+	type silence Silence
+	return json.Marshal(struct {
+		*silence
+		State SilenceState `json:"state"`
+	}{
+		silence: (*silence)(s),
+		State:   s.state(s.now()),
+	})
+}
+
+func (s *Silence) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+func (s *Silence) state(now time.Time) SilenceState {
+	switch {
+	case now.Before(s.StartsAt):
+		return SilenceStatePending
+	case now.Before(s.EndsAt):
+		return SilenceStateActive
+	default:
+		return SilenceStateExpired
+	}
+}
+
+func (s *Silence) matches(service *Service) bool {
+	return slices.Contains(s.Services, service.Name) || service.hasAnyTag(s.Tags)
+}
+
+func newSilenceID() string {
+	var buf [8]byte
+	rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
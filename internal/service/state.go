@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"service-uptime-center/notification"
+)
+
+type State string
+
+const (
+	StateOK     State = "ok"
+	StateLate   State = "late"
+	StateDown   State = "down"
+	StatePaused State = "paused"
+)
+
+// state derives the state of the service from its pulses. A service is late once WarningThreshold of its
+// timeout has passed without a pulse, services without a threshold go from ok to down directly.
+func (s *Service) state(now time.Time) State {
+	if s.Pause != nil {
+		return StatePaused
+	}
+
+	switch {
+	case !now.Before(s.downSince()):
+		return StateDown
+	case s.WarningThreshold > 0 && now.Sub(s.LastPulse) >= s.warningTimeout():
+		return StateLate
+	default:
+		return StateOK
+	}
+}
+
+func (s *Service) warningTimeout() time.Duration {
+	return time.Duration(s.WarningThreshold * float64(s.timeout()))
+}
+
+// warningTargets resolves where warnings about the service being late are sent, in order of precedence: the warning
+// notifiers of the service itself, the first route matching one of its tags that has warning notifiers, the global
+// warning notifiers and finally wherever problems with the service are sent.
+func (s *Service) warningTargets(routes []Route, warningDefaults []string, defaults notification.ProtocolTargets) notification.ProtocolTargets {
+	primary := s.WarningNotifiers
+	if len(primary) == 0 {
+		for _, route := range routes {
+			if len(route.WarningNotifiers) != 0 && s.hasAnyTag(route.Tags) {
+				primary = route.WarningNotifiers
+				break
+			}
+		}
+	}
+	if len(primary) == 0 {
+		primary = warningDefaults
+	}
+	if len(primary) == 0 {
+		return s.notificationTargets(routes, defaults)
+	}
+
+	return notification.ProtocolTargets{
+		Primary: primary,
+		Fallback: slices.DeleteFunc(slices.Clone(defaults.Fallback), func(protocol string) bool {
+			return slices.Contains(primary, protocol)
+		}),
+	}
+}
+
+// handleLateServices sends one warning for each service that became late since the last call. Services that are
+// already down are left to handleProblematicServices.
+func (m *Manager) handleLateServices(notificationManager *notification.Manager, warningNotifiers []string, targets notification.ProtocolTargets) {
+	m.mutex.Lock()
+
+	now := m.clock.Now()
+	reports := newReportsByTargets(newLineReport)
+	for _, service := range m.services {
+		state := service.state(now)
+		if state == StateOK {
+			service.lateNotified = false
+		}
+		if state != StateLate || service.lateNotified {
+			continue
+		}
+
+		service.lateNotified = true
+		sinceLastPulse := now.Sub(service.LastPulse).Round(time.Second)
+		m.recordEvent(service, EventLate, now, fmt.Sprintf("no pulse for %s", sinceLastPulse))
+
+		if service.isFlapping() {
+			slog.Info("Leaving out late service from warning because it's flapping.", "service", service.Name, "flapping since", service.FlappingSince)
+			continue
+		}
+		if reason, silenced := m.silencedBy(service, now); silenced {
+			slog.Info("Leaving out late service from warning because it's silenced.", "service", service.Name, "silenced by", reason)
+			continue
+		}
+
+		remaining := service.downSince().Sub(now).Round(time.Second)
+		reports.forTargets(service.warningTargets(m.cfg.Routes, warningNotifiers, targets)).
+			add(service, fmt.Sprintf("%s, %s, %s, %s", service.Name, service.LastPulse.String(), sinceLastPulse, remaining))
+	}
+
+	m.mutex.Unlock()
+
+	for _, report := range reports.reports {
+		m.sendReport(notificationManager, report.targets, report.services, notification.SendData{
+			Title: fmt.Sprintf("%d services are late", len(report.lines)),
+			Body:  report.body("Service Name, Last Pulse, Since Last Pulse, Down In\n"),
+		})
+	}
+}
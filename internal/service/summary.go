@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/internal/cron"
+	"service-uptime-center/notification"
+)
+
+// SummaryReportSettings schedules the periodic summary report. Without a schedule the report is sent every
+// successful_report_cooldown.
+type SummaryReportSettings struct {
+	Schedule string `yaml:"schedule"`
+	schedule *cron.Schedule
+}
+
+func (s *SummaryReportSettings) validate() error {
+	if len(s.Schedule) == 0 {
+		return nil
+	}
+
+	schedule, err := cron.Parse(s.Schedule)
+	if err != nil {
+		return fmt.Errorf("%w: %w", apperror.ErrInvalidSummaryReport, err)
+	}
+	s.schedule = schedule
+	return nil
+}
+
+// next returns when the report following the one at t is due, falling back to the interval without a schedule.
+func (s *SummaryReportSettings) next(t time.Time, interval time.Duration) time.Time {
+	if s.schedule != nil {
+		if next := s.schedule.Next(t); !next.IsZero() {
+			return next
+		}
+	}
+	return t.Add(interval)
+}
+
+// weeklyUptimeDue reports whether the weekly uptime goes into the summary report sent at now and marks it as sent if
+// so. It's added once a week, to the last report before a week has passed since it was last added, whatever the
+// schedule of the reports.
+func (m *Manager) weeklyUptimeDue(now time.Time) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.cfg.Uptime.WeeklySummary {
+		return false
+	}
+	next := m.cfg.SummaryReport.next(now, m.instr.Timings.SuccessfulReportCooldown)
+	if !next.After(m.lastWeeklyUptime.Add(7 * 24 * time.Hour)) {
+		return false
+	}
+	m.lastWeeklyUptime = now
+	return true
+}
+
+// incidentsSince counts the incidents that were ongoing at some point after since, including the current one.
+func (s *Service) incidentsSince(since time.Time) int {
+	count := 0
+	for _, incident := range s.incidents {
+		if incident.End.After(since) {
+			count++
+		}
+	}
+	if !s.IncidentStart.IsZero() {
+		count++
+	}
+	return count
+}
+
+// summaryReport builds the periodic summary covering everything since the previous one and marks it as sent.
+func (m *Manager) summaryReport(now time.Time) notification.SendData {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	since := m.lastSummary
+	m.lastSummary = now
+
+	var b strings.Builder
+	var silenced, acknowledged, paused []string
+	healthy := 0
+	b.WriteString("Service Name, State, Last Pulse, Incidents Since Last Report\n")
+	for _, service := range m.services {
+		state := service.state(now)
+		switch state {
+		case StateOK:
+			healthy++
+		case StatePaused:
+			line := service.Name
+			if len(service.Pause.Comment) != 0 {
+				line += ": " + service.Pause.Comment
+			}
+			paused = append(paused, line)
+		}
+		fmt.Fprintf(&b, "%s, %s, %s, %d\n", service.Name, state, service.LastPulse.Format(time.RFC3339), service.incidentsSince(since))
+
+		if reason, ok := m.silencedBy(service, now); ok {
+			silenced = append(silenced, fmt.Sprintf("%s (%s)", service.Name, reason))
+		}
+		if ack := service.Acknowledgement; ack.isActive(now) {
+			line := service.Name
+			if len(ack.Comment) != 0 {
+				line += ": " + ack.Comment
+			}
+			acknowledged = append(acknowledged, line)
+		}
+	}
+
+	writeSection := func(heading string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n", heading, len(lines))
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+	writeSection("Silenced", silenced)
+	writeSection("Acknowledged", acknowledged)
+	writeSection("Paused", paused)
+
+	// Paused services aren't monitored, they're listed but don't count as unhealthy.
+	title := "Service Uptime Center running without any issues."
+	if monitored := len(m.services) - len(paused); healthy != monitored {
+		title = fmt.Sprintf("Service Uptime Center summary: %d of %d services healthy", healthy, monitored)
+	}
+
+	return notification.SendData{
+		Title: title,
+		Body:  b.String(),
+	}
+}
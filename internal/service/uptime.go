@@ -0,0 +1,217 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"service-uptime-center/internal/store"
+)
+
+type Incident struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type UptimeWindow struct {
+	Name     string
+	Duration time.Duration
+}
+
+var UptimeWindows = []UptimeWindow{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+	{"90d", 90 * 24 * time.Hour},
+}
+
+// incidentRetention is how long resolved incidents are kept, it covers the longest uptime window.
+var incidentRetention = UptimeWindows[len(UptimeWindows)-1].Duration
+
+// UptimeSettings controls uptime reporting, the statistics themselves are always tracked.
+type UptimeSettings struct {
+	// WeeklySummary adds the uptime of every service over the last 7 days to the summary report once a week, to the
+	// last report before a week has passed since it was last added.
+	WeeklySummary bool `yaml:"weekly_summary"`
+}
+
+type UptimeStats struct {
+	UptimePercent float64 `json:"uptime_percent"`
+	Incidents     int     `json:"incidents"`
+	Downtime      string  `json:"downtime"`
+	// MeanTimeToRecovery only considers incidents that were resolved within the window.
+	MeanTimeToRecovery string `json:"mean_time_to_recovery,omitempty"`
+}
+
+type ServiceUptime struct {
+	Name    string                 `json:"name"`
+	Since   time.Time              `json:"monitored_since"`
+	Windows map[string]UptimeStats `json:"windows"`
+}
+
+type UptimeReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Services    []ServiceUptime `json:"services"`
+}
+
+func (s *Service) addIncident(incident Incident) {
+	s.incidents = append(s.incidents, incident)
+
+	cutoff := incident.End.Add(-incidentRetention)
+	s.incidents = slices.DeleteFunc(s.incidents, func(i Incident) bool { return i.End.Before(cutoff) })
+}
+
+// uptime computes the statistics for the part of the window the service has been monitored, the ongoing incident
+// counts as downtime up until now.
+func (s *Service) uptime(now time.Time, window time.Duration) UptimeStats {
+	from := now.Add(-window)
+	if s.MonitoredSince.After(from) {
+		from = s.MonitoredSince
+	}
+
+	var downtime, recoveryTotal time.Duration
+	var count, resolved int
+	countOverlap := func(incident Incident) bool {
+		start := incident.Start
+		if start.Before(from) {
+			start = from
+		}
+		end := incident.End
+		if end.After(now) {
+			end = now
+		}
+		if !end.After(start) {
+			return false
+		}
+
+		count++
+		downtime += end.Sub(start)
+		return true
+	}
+
+	for _, incident := range s.incidents {
+		if countOverlap(incident) {
+			resolved++
+			recoveryTotal += incident.End.Sub(incident.Start)
+		}
+	}
+
+	ongoing := s.IncidentStart
+	if ongoing.IsZero() && s.isProblematic() {
+		ongoing = s.downSince()
+	}
+	if !ongoing.IsZero() {
+		countOverlap(Incident{Start: ongoing, End: now})
+	}
+
+	stats := UptimeStats{
+		UptimePercent: 100,
+		Incidents:     count,
+		Downtime:      downtime.Round(time.Second).String(),
+	}
+	if observed := now.Sub(from); observed > 0 {
+		stats.UptimePercent = 100 * (1 - float64(downtime)/float64(observed))
+	}
+	if resolved > 0 {
+		stats.MeanTimeToRecovery = (recoveryTotal / time.Duration(resolved)).Round(time.Second).String()
+	}
+	return stats
+}
+
+func (s *Service) uptimeWindows(now time.Time) map[string]UptimeStats {
+	windows := make(map[string]UptimeStats, len(UptimeWindows))
+	for _, window := range UptimeWindows {
+		windows[window.Name] = s.uptime(now, window.Duration)
+	}
+	return windows
+}
+
+func (m *Manager) UptimeReport() UptimeReport {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	now := m.clock.Now()
+	report := UptimeReport{
+		GeneratedAt: now,
+		Services:    make([]ServiceUptime, 0, len(m.services)),
+	}
+	for _, service := range m.services {
+		report.Services = append(report.Services, ServiceUptime{
+			Name:    service.Name,
+			Since:   service.MonitoredSince,
+			Windows: service.uptimeWindows(now),
+		})
+	}
+	return report
+}
+
+// uptimeSummary formats one line per service with its uptime over the window, meant for notification bodies.
+func (m *Manager) uptimeSummary(window time.Duration) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	now := m.clock.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "Uptime over the last %s:\n", window)
+	for _, service := range m.services {
+		stats := service.uptime(now, window)
+		fmt.Fprintf(&b, "%s: %.3f%% (%d incidents, downtime %s", service.Name, stats.UptimePercent, stats.Incidents, stats.Downtime)
+		if len(stats.MeanTimeToRecovery) != 0 {
+			fmt.Fprintf(&b, ", MTTR %s", stats.MeanTimeToRecovery)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// uptimeState is the persisted part of the uptime statistics of a service.
+type uptimeState struct {
+	MonitoredSince time.Time  `json:"monitored_since"`
+	Incidents      []Incident `json:"incidents"`
+}
+
+func (m *Manager) uptimePath() string {
+	return filepath.Join(m.cfg.StateDirectory, "uptime.json")
+}
+
+func (m *Manager) loadUptime() error {
+	var states map[string]uptimeState
+	if err := store.Load(m.uptimePath(), &states); err != nil {
+		return err
+	}
+
+	for name, state := range states {
+		service, exists := m.lookup[name]
+		if !exists {
+			continue
+		}
+		if !state.MonitoredSince.IsZero() && state.MonitoredSince.Before(service.MonitoredSince) {
+			service.MonitoredSince = state.MonitoredSince
+		}
+		service.incidents = state.Incidents
+	}
+
+	return nil
+}
+
+// saveUptime persists the uptime state if a state directory is configured, callers must hold the mutex.
+func (m *Manager) saveUptime() {
+	if len(m.cfg.StateDirectory) == 0 {
+		return
+	}
+
+	states := make(map[string]uptimeState, len(m.services))
+	for _, service := range m.services {
+		states[service.Name] = uptimeState{
+			MonitoredSince: service.MonitoredSince,
+			Incidents:      service.incidents,
+		}
+	}
+
+	if err := store.Save(m.uptimePath(), states); err != nil {
+		slog.Error("Failed to persist uptime statistics, they will be reset on restart", "path", m.uptimePath(), "error", err)
+	}
+}
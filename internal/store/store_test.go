@@ -0,0 +1,40 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testState struct {
+	Names []string `json:"names"`
+	Count int      `json:"count"`
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	expected := testState{Names: []string{"a", "b"}, Count: 2}
+
+	if err := Save(path, expected); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	var got testState
+	if err := Load(path, &got); err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("state mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	got := testState{Count: 7}
+	if err := Load(filepath.Join(t.TempDir(), "missing.json"), &got); err != nil {
+		t.Fatalf("expected missing file to be ignored, got %v", err)
+	}
+	if got.Count != 7 {
+		t.Errorf("expected value to be left untouched, got %+v", got)
+	}
+}
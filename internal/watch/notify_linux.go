@@ -0,0 +1,89 @@
+//go:build linux
+
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"syscall"
+)
+
+// inotifyMask covers files being written, replaced and removed.
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM |
+	syscall.IN_MOVED_TO | syscall.IN_ATTRIB
+
+// inotify doesn't parse the events it reads, any activity in a watched directory is reported.
+type inotify struct {
+	fd   int
+	file *os.File
+	// watches maps the watched directories to their watch descriptors.
+	watches map[string]int
+	c       chan struct{}
+}
+
+func newNotifier() (notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+
+	n := &inotify{
+		fd: fd,
+		// Non-blocking, so reads go through the runtime poller and are interrupted by close.
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		watches: make(map[string]int),
+		c:       make(chan struct{}, 1),
+	}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotify) read() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		if _, err := n.file.Read(buf); err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				slog.Error("Failed to read inotify events", "error", err)
+			}
+			return
+		}
+		select {
+		case n.c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *inotify) watch(dirs []string) error {
+	for dir, wd := range n.watches {
+		if !slices.Contains(dirs, dir) {
+			syscall.InotifyRmWatch(n.fd, uint32(wd))
+			delete(n.watches, dir)
+		}
+	}
+
+	var errs []error
+	for _, dir := range dirs {
+		if _, ok := n.watches[dir]; ok {
+			continue
+		}
+		wd, err := syscall.InotifyAddWatch(n.fd, dir, inotifyMask)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to watch %s: %w", dir, err))
+			continue
+		}
+		n.watches[dir] = wd
+	}
+	return errors.Join(errs...)
+}
+
+func (n *inotify) events() <-chan struct{} {
+	return n.c
+}
+
+func (n *inotify) close() error {
+	return n.file.Close()
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+func newNotifier() (notifier, error) {
+	return nil, errors.ErrUnsupported
+}
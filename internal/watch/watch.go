@@ -0,0 +1,148 @@
+// Package watch notices changes to files, such as the config file and the secrets it references.
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is how often the files are compared if the platform can't notify about changes.
+	DefaultPollInterval = 5 * time.Second
+	// settleDelay is how long to wait for further activity before comparing the files, editors and tools rotating
+	// secrets often write a file several times in a row.
+	settleDelay = 200 * time.Millisecond
+)
+
+// notifier reports activity in directories, it's only a hint to compare the files again.
+type notifier interface {
+	// watch replaces the watched directories.
+	watch(dirs []string) error
+	events() <-chan struct{}
+	close() error
+}
+
+// Watcher calls onChange whenever the content of one of the watched files changes. It's notified about changes by
+// the platform where possible, see notify_linux.go, and polls the files otherwise.
+type Watcher struct {
+	files        func() []string
+	onChange     func()
+	pollInterval time.Duration
+	// newNotifier creates the notifier of the platform, it's replaced in tests.
+	newNotifier func() (notifier, error)
+}
+
+// New watches the files returned by files, which is called again after every change, so a change can add or remove
+// watched files.
+func New(files func() []string, onChange func()) *Watcher {
+	return &Watcher{
+		files:        files,
+		onChange:     onChange,
+		pollInterval: DefaultPollInterval,
+		newNotifier:  newNotifier,
+	}
+}
+
+// Run watches the files until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	files := w.files()
+	fingerprints := fingerprint(files)
+
+	var events <-chan struct{}
+	var poll <-chan time.Time
+	n, err := w.newNotifier()
+	if err == nil {
+		if err = n.watch(dirs(files)); err != nil {
+			n.close()
+		}
+	}
+	if err != nil {
+		slog.Warn("Can't be notified about file changes, polling instead", "interval", w.pollInterval, "error", err)
+		n = nil
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	} else {
+		defer n.close()
+		events = n.events()
+	}
+
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events:
+			settled = time.After(settleDelay)
+			continue
+		case <-settled:
+			settled = nil
+		case <-poll:
+		}
+
+		current := fingerprint(files)
+		if maps.Equal(current, fingerprints) {
+			continue
+		}
+		slog.Info("Watched files changed", "files", changed(fingerprints, current))
+		w.onChange()
+
+		// Files that are still watched keep the fingerprint from before the change, so a write during onChange is
+		// noticed on the next comparison.
+		files = w.files()
+		next := fingerprint(files)
+		for file := range next {
+			if previous, ok := current[file]; ok {
+				next[file] = previous
+			}
+		}
+		fingerprints = next
+		if n != nil {
+			if err := n.watch(dirs(files)); err != nil {
+				slog.Error("Failed to watch the directories of the files", "error", err)
+			}
+		}
+	}
+}
+
+// fingerprint hashes the content of the files, a missing or unreadable file has the zero hash.
+func fingerprint(files []string) map[string][sha256.Size]byte {
+	fingerprints := make(map[string][sha256.Size]byte, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			fingerprints[file] = [sha256.Size]byte{}
+			continue
+		}
+		fingerprints[file] = sha256.Sum256(content)
+	}
+	return fingerprints
+}
+
+func changed(before, after map[string][sha256.Size]byte) []string {
+	var files []string
+	for file, hash := range after {
+		if previous, ok := before[file]; !ok || previous != hash {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+// dirs returns the directories containing the files. Directories are watched instead of the files themselves, since
+// files replaced by a rename, as most editors and secret managers do, would no longer be watched.
+func dirs(files []string) []string {
+	dirs := make([]string, 0, len(files))
+	for _, file := range files {
+		dirs = append(dirs, filepath.Dir(file))
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
@@ -0,0 +1,81 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		newNotifier func() (notifier, error)
+	}{
+		{"notify", newNotifier},
+		{"poll", func() (notifier, error) { return nil, errors.ErrUnsupported }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			config := filepath.Join(dir, "config.yaml")
+			secret := filepath.Join(t.TempDir(), "token.txt")
+			write := func(path, content string) {
+				t.Helper()
+				// Replace the file like editors and secret managers do.
+				tmp := path + ".tmp"
+				if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+					t.Fatalf("failed to write %s: %v", tmp, err)
+				}
+				if err := os.Rename(tmp, path); err != nil {
+					t.Fatalf("failed to rename %s: %v", tmp, err)
+				}
+			}
+			write(config, "services: []")
+			write(secret, "token-1")
+
+			changes := make(chan struct{}, 10)
+			watcher := New(func() []string { return []string{config, secret} }, func() { changes <- struct{}{} })
+			watcher.pollInterval = 10 * time.Millisecond
+			watcher.newNotifier = test.newNotifier
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan struct{})
+			go func() {
+				defer close(stopped)
+				watcher.Run(ctx)
+			}()
+			defer func() {
+				cancel()
+				<-stopped
+			}()
+			// Give the watcher time to take its first fingerprints.
+			time.Sleep(50 * time.Millisecond)
+
+			expectChange := func(expected bool) {
+				t.Helper()
+				select {
+				case <-changes:
+					if !expected {
+						t.Fatal("expected no change to be reported")
+					}
+				case <-time.After(time.Second):
+					if expected {
+						t.Fatal("expected a change to be reported")
+					}
+				}
+			}
+
+			write(secret, "token-2")
+			expectChange(true)
+
+			// Rewriting the same content is not a change.
+			write(config, "services: []")
+			expectChange(false)
+
+			write(config, "services: [{name: web}]")
+			expectChange(true)
+		})
+	}
+}
@@ -1,16 +1,21 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"service-uptime-center/config"
 	"service-uptime-center/internal/app"
 	"service-uptime-center/internal/app/apperror"
+	"service-uptime-center/internal/app/timings"
 	"service-uptime-center/internal/app/util"
 	"service-uptime-center/internal/cli"
 	"service-uptime-center/internal/server"
-	"service-uptime-center/internal/service"
+	"service-uptime-center/internal/watch"
 	"service-uptime-center/notification"
 )
 
@@ -56,7 +61,7 @@ func main() {
 		os.Exit(apperror.CodeInvalidConfig)
 	}
 
-	allNotifiers := append(cfg.Notifiers, cfg.FallbackNotifiers...)
+	allNotifiers := cfg.AllNotifiers()
 	slog.Info("running startup authentication tests", "notifiers", allNotifiers)
 	authResults := managerLocator.NotificationManager.TestAuth(allNotifiers)
 	for _, r := range authResults {
@@ -66,14 +71,61 @@ func main() {
 		}
 	}
 
-	server.SetupEndpoints(pw, managerLocator.ServiceManager, managerLocator.NotificationManager, allNotifiers)
-	managerLocator.ServiceManager.StartMonitoring(managerLocator.NotificationManager, service.MonitoringInstructions{
-		Timings: &cfg.Timings,
-		Notifiers: notification.ProtocolTargets{
-			Primary:  cfg.Notifiers,
-			Fallback: cfg.FallbackNotifiers,
-		},
-	})
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	managerLocator.ServiceManager.StartMonitoring(ctx, managerLocator.NotificationManager, cfg.MonitoringInstructions())
+	reloader := app.NewReloader(ctx, args.ConfigPath, cfg, managerLocator)
 
-	server.ServeAndAwaitTermination(args.Port)
+	server.SetupEndpoints(pw, managerLocator.ServiceManager, managerLocator.NotificationManager, reloader)
+
+	reload := func() {
+		slog.Info("reloading config", "path", args.ConfigPath)
+		if err := reloader.Reload(); err != nil {
+			slog.Error("failed to reload config, keeping the previous one", "error", err)
+		}
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reload()
+		}
+	}()
+	if args.WatchConfig {
+		go watch.New(reloader.WatchedFiles, reload).Run(ctx)
+	}
+
+	code := 0
+	shutdownTimeout := cmp.Or(cfg.Timings.ShutdownTimeout, timings.DefaultShutdownTimeout)
+	if err := server.ServeAndAwaitTermination(ctx, args.Port, shutdownTimeout); err != nil {
+		slog.Error("http server stopped unexpectedly", "error", err)
+		code = apperror.CodeServerFailed
+	}
+	stop()
+	signal.Stop(hup)
+
+	slog.Info("shutting down", "timeout", shutdownTimeout)
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := reloader.WaitForChecks(drainCtx); err != nil {
+		slog.Error("failed to stop checks", "error", err)
+		code = cmp.Or(code, apperror.CodeShutdownFailed)
+	}
+	if err := managerLocator.ServiceManager.Shutdown(drainCtx); err != nil {
+		slog.Error("failed to shut down monitoring cleanly", "error", err)
+		code = cmp.Or(code, apperror.CodeShutdownFailed)
+	}
+
+	if cfg := reloader.Config(); cfg.ShutdownNotice {
+		err := managerLocator.NotificationManager.SendWithFallback(cfg.MonitoringInstructions().Notifiers, notification.SendData{
+			Title: "Service Uptime Center is shutting down",
+			Body:  "Monitoring has stopped, problems will not be reported until it is started again.",
+		})
+		if err != nil {
+			slog.Error("failed to send shutdown notice", "error", err)
+		}
+	}
+
+	slog.Info("shutdown complete", "exit code", code)
+	cancel()
+	os.Exit(code)
 }
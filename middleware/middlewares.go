@@ -17,6 +17,9 @@ var (
 	MiddlewareMethodGet = func(w http.ResponseWriter, r *http.Request) bool {
 		return middlewareMethodCheck(w, r, http.MethodGet)
 	}
+	MiddlewareMethodDelete = func(w http.ResponseWriter, r *http.Request) bool {
+		return middlewareMethodCheck(w, r, http.MethodDelete)
+	}
 	MiddlewareLogger = func(_ http.ResponseWriter, r *http.Request) bool {
 		slog.Info("HTTP Request",
 			"method", r.Method,
@@ -40,6 +43,13 @@ func ApplyMiddleware(w http.ResponseWriter, r *http.Request, middlewares []Middl
 	return true
 }
 
+// CreateMethodsMiddleware allows any of the given methods, for endpoints whose handler dispatches on the method itself.
+func CreateMethodsMiddleware(methods ...string) Middleware {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		return middlewareMethodCheck(w, r, methods...)
+	}
+}
+
 func CreateAuthMiddleware(authToken string) Middleware {
 	return func(w http.ResponseWriter, r *http.Request) bool {
 		writeResponse := func(msg string, code int) {
@@ -52,6 +52,31 @@ func TestMiddlewareMethods(t *testing.T) {
 			MiddlewareMethodPost,
 			false,
 		},
+		{
+			http.MethodDelete,
+			MiddlewareMethodDelete,
+			true,
+		},
+		{
+			http.MethodGet,
+			MiddlewareMethodDelete,
+			false,
+		},
+		{
+			http.MethodGet,
+			CreateMethodsMiddleware(http.MethodGet, http.MethodPost),
+			true,
+		},
+		{
+			http.MethodPost,
+			CreateMethodsMiddleware(http.MethodGet, http.MethodPost),
+			true,
+		},
+		{
+			http.MethodDelete,
+			CreateMethodsMiddleware(http.MethodGet, http.MethodPost),
+			false,
+		},
 	} {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(testCase.method, "/test", nil)
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"mime"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 func MiddlewareContentTypeCheck(w http.ResponseWriter, r *http.Request, expectedType string) bool {
@@ -33,10 +35,10 @@ func MiddlewareContentTypeCheck(w http.ResponseWriter, r *http.Request, expected
 	return true
 }
 
-func middlewareMethodCheck(w http.ResponseWriter, r *http.Request, method string) bool {
-	if r.Method != method {
-		slog.Warn("Middleware BLOCKED request - Invalid Method!", "expected", method, "got", r.Method)
-		w.Header().Set("Allow", method)
+func middlewareMethodCheck(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	if !slices.Contains(methods, r.Method) {
+		slog.Warn("Middleware BLOCKED request - Invalid Method!", "expected", methods, "got", r.Method)
+		w.Header().Set("Allow", strings.Join(methods, ", "))
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return false
 	}
@@ -31,7 +31,9 @@ type NotifyProtocol interface {
 }
 
 type Manager struct {
+	// protocols is replaced as a whole on reload, never modified, so it can be used after the mutex is released.
 	protocols map[string]protocolEntry
+	mutex     sync.RWMutex
 }
 
 type protocolEntry struct {
@@ -45,6 +47,17 @@ type ManagerConfig struct {
 	Ntfy NtfyConfig `yaml:"ntfy"`
 }
 
+// SecretFiles returns the files the notifier secrets are read from.
+func (m *ManagerConfig) SecretFiles() []string {
+	var files []string
+	for _, file := range []string{m.Mail.SMTP.PasswordFile, m.Ntfy.TokenFile} {
+		if len(file) != 0 {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
 func (m *ManagerConfig) ValidateFor(notifiers []string, manager *Manager) error {
 	if len(notifiers) == 0 {
 		return nil
@@ -56,7 +69,7 @@ func (m *ManagerConfig) ValidateFor(notifiers []string, manager *Manager) error
 			return fmt.Errorf("%w: %s", ErrDuplicateNotifyProtocol, protocol)
 		}
 		seen[protocol] = struct{}{}
-		entry, ok := manager.protocols[protocol]
+		entry, ok := manager.currentProtocols()[protocol]
 		if !ok {
 			return ErrInvalidProtocol
 		}
@@ -72,24 +85,44 @@ func (m *ManagerConfig) ValidateFor(notifiers []string, manager *Manager) error
 }
 
 func NewManager(cfg *ManagerConfig) *Manager {
+	return &Manager{
+		protocols: newProtocols(cfg),
+	}
+}
+
+func newProtocols(cfg *ManagerConfig) map[string]protocolEntry {
 	mailNotifier := newMailNotifier(&cfg.Mail)
 	ntfyNotifier := newNtfyNotifier(&cfg.Ntfy)
-	return &Manager{
-		protocols: map[string]protocolEntry{
-			"mail": {
-				notify:   mailNotifier,
-				validate: cfg.Mail.Validate,
-				testAuth: mailNotifier.testAuth,
-			},
-			"ntfy": {
-				notify:   ntfyNotifier,
-				validate: cfg.Ntfy.Validate,
-				testAuth: ntfyNotifier.testAuth,
-			},
+	return map[string]protocolEntry{
+		"mail": {
+			notify:   mailNotifier,
+			validate: cfg.Mail.Validate,
+			testAuth: mailNotifier.testAuth,
+		},
+		"ntfy": {
+			notify:   ntfyNotifier,
+			validate: cfg.Ntfy.Validate,
+			testAuth: ntfyNotifier.testAuth,
 		},
 	}
 }
 
+// Reload switches to the notifier settings of cfg at once, notifications that are being sent finish with the
+// previous settings.
+func (p *Manager) Reload(cfg *ManagerConfig) {
+	protocols := newProtocols(cfg)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.protocols = protocols
+}
+
+func (p *Manager) currentProtocols() map[string]protocolEntry {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.protocols
+}
+
 type AuthTestResult struct {
 	Protocol string
 	Err      error
@@ -102,8 +135,9 @@ func (p *Manager) TestAuth(protocols []string) []AuthTestResult {
 		results []AuthTestResult
 	)
 
+	current := p.currentProtocols()
 	for _, protocol := range protocols {
-		entry, ok := p.protocols[protocol]
+		entry, ok := current[protocol]
 		if !ok {
 			slog.Error("auth test skipped, unknown protocol", "protocol", protocol)
 			results = append(results, AuthTestResult{Protocol: protocol, Err: ErrInvalidProtocol})
@@ -129,7 +163,7 @@ func (p *Manager) TestAuth(protocols []string) []AuthTestResult {
 }
 
 func (p *Manager) Send(protocols []string, data SendData) error {
-	failures := p.sendAll(protocols, data)
+	failures := sendAll(p.currentProtocols(), protocols, data)
 	if len(failures) != 0 {
 		logSendFailures(failures)
 		return formatSendFailures(failures)
@@ -139,13 +173,14 @@ func (p *Manager) Send(protocols []string, data SendData) error {
 }
 
 func (p *Manager) SendWithFallback(targets ProtocolTargets, data SendData) error {
-	failures := p.sendAll(targets.Primary, data)
+	current := p.currentProtocols()
+	failures := sendAll(current, targets.Primary, data)
 	if len(failures) > 0 && len(targets.Fallback) > 0 {
 		fallbackData := SendData{
 			Title: "Fallback notification: primary notifier failed",
 			Body:  formatFallbackBody(failures, data),
 		}
-		fallbackFailures := p.sendAll(targets.Fallback, fallbackData)
+		fallbackFailures := sendAll(current, targets.Fallback, fallbackData)
 		for _, fallbackFailure := range fallbackFailures {
 			failures = append(failures, sendFailure{
 				protocol: fallbackFailure.protocol + " (fallback)",
@@ -167,10 +202,10 @@ type sendFailure struct {
 	err      error
 }
 
-func (p *Manager) sendAll(protocols []string, data SendData) []sendFailure {
+func sendAll(available map[string]protocolEntry, protocols []string, data SendData) []sendFailure {
 	var failures []sendFailure
 	for _, protocol := range protocols {
-		entry, ok := p.protocols[protocol]
+		entry, ok := available[protocol]
 		if !ok {
 			failures = append(failures, sendFailure{protocol: protocol, err: ErrInvalidProtocol})
 			continue
@@ -2,6 +2,9 @@ package notification
 
 import (
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -88,3 +91,24 @@ func TestSendInvalidProtocolReturnsErrNotificationFailed(t *testing.T) {
 		t.Fatalf("expected ErrNotificationFailed, got %v", err)
 	}
 }
+
+func TestReloadSwitchesSettings(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer server.Close()
+
+	manager := NewManager(&ManagerConfig{Ntfy: NtfyConfig{Server: server.URL, Topic: "before"}})
+	if err := manager.Send([]string{"ntfy"}, SendData{Title: "Alert", Body: "Body"}); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+	manager.Reload(&ManagerConfig{Ntfy: NtfyConfig{Server: server.URL, Topic: "after"}})
+	if err := manager.Send([]string{"ntfy"}, SendData{Title: "Alert", Body: "Body"}); err != nil {
+		t.Fatalf("expected send to succeed, got %v", err)
+	}
+
+	if !slices.Equal(paths, []string{"/before", "/after"}) {
+		t.Fatalf("expected the reloaded topic to be used, got %v", paths)
+	}
+}